@@ -1,9 +1,11 @@
 package agollo
 
 import (
+	"context"
 	"errors"
 	"log"
 	"os"
+	"time"
 )
 
 var (
@@ -23,6 +25,10 @@ func (m *Agollo) Start() error {
 	return m.Client.Start()
 }
 
+func (m *Agollo) StartContext(ctx context.Context) error {
+	return m.Client.StartContext(ctx)
+}
+
 func (m *Agollo) StartWithConfFile(name string) error {
 	conf, err := NewConf(name)
 	if err != nil {
@@ -32,27 +38,53 @@ func (m *Agollo) StartWithConfFile(name string) error {
 }
 
 func (m *Agollo) StartWithConf(conf *Conf) error {
+	return m.StartWithConfContext(context.Background(), conf)
+}
+
+func (m *Agollo) StartWithConfContext(ctx context.Context, conf *Conf) error {
 	m.Client = NewClient(conf)
 
-	return m.Client.Start()
+	return m.Client.StartContext(ctx)
 }
 
 func (m *Agollo) Stop() error {
 	return m.Client.Stop()
 }
 
+func (m *Agollo) StopContext(ctx context.Context) error {
+	return m.Client.StopContext(ctx)
+}
+
+func (m *Agollo) GetPollerState() PollerState {
+	return m.Client.GetPollerState()
+}
+
+func (m *Agollo) Ready(ctx context.Context) error {
+	return m.Client.Ready(ctx)
+}
+
+func (m *Agollo) WaitReady(timeout time.Duration) error {
+	return m.Client.WaitReady(timeout)
+}
+
+func (m *Agollo) GetNamespaceStatus(namespace string) (NamespaceStatus, bool) {
+	return m.Client.GetNamespaceStatus(namespace)
+}
+
+func (m *Agollo) GetNamespaceStatuses() []NamespaceStatus {
+	return m.Client.GetNamespaceStatuses()
+}
+
 func (m *Agollo) StartWatchUpdate() {
-	ceChan := m.Client.WatchUpdate()
+	m.Client.StartWatchUpdate()
+}
 
-	go func() {
-		for {
-			ce := <-ceChan
+func (m *Agollo) WaitDispatch(ctx context.Context) error {
+	return m.Client.WaitDispatch(ctx)
+}
 
-			for _, ob := range m.Client.getObservers() {
-				ob.HandleChangeEvent(ce)
-			}
-		}
-	}()
+func (m *Agollo) GetObserverStats() []ObserverStats {
+	return m.Client.GetObserverStats()
 }
 
 func (m *Agollo) RegisterObserver(observer ChangeEventObserver) (recall func()) {
@@ -66,6 +98,14 @@ func (m *Agollo) SubscribeToNamespaces(namespaces ...string) error {
 	return m.Client.SubscribeToNamespaces(namespaces...)
 }
 
+func (m *Agollo) SubscribeToNamespacesContext(ctx context.Context, namespaces ...string) error {
+	return m.Client.SubscribeToNamespacesContext(ctx, namespaces...)
+}
+
+func (m *Agollo) GoOnline(ctx context.Context) error {
+	return m.Client.GoOnline(ctx)
+}
+
 func (m *Agollo) GetStringWithNamespace(namespace, key string) (string, bool) {
 	return m.Client.GetStringWithNamespace(namespace, key)
 }
@@ -74,6 +114,22 @@ func (m *Agollo) GetString(key string) (string, bool) {
 	return m.Client.GetString(key)
 }
 
+func (m *Agollo) GetStringWithNamespaceE(namespace, key string) (string, error) {
+	return m.Client.GetStringWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetStringE(key string) (string, error) {
+	return m.Client.GetStringE(key)
+}
+
+func (m *Agollo) GetStringWithNamespaceOr(namespace, key string, def string) string {
+	return m.Client.GetStringWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetStringOr(key string, def string) string {
+	return m.Client.GetStringOr(key, def)
+}
+
 func (m *Agollo) GetIntWithNamespace(namespace, key string) (int, bool) {
 	return m.Client.GetIntWithNamespace(namespace, key)
 }
@@ -82,6 +138,94 @@ func (m *Agollo) GetInt(key string) (int, bool) {
 	return m.Client.GetInt(key)
 }
 
+func (m *Agollo) GetIntWithNamespaceE(namespace, key string) (int, error) {
+	return m.Client.GetIntWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetIntE(key string) (int, error) {
+	return m.Client.GetIntE(key)
+}
+
+func (m *Agollo) GetIntWithNamespaceOr(namespace, key string, def int) int {
+	return m.Client.GetIntWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetIntOr(key string, def int) int {
+	return m.Client.GetIntOr(key, def)
+}
+
+func (m *Agollo) GetInt64WithNamespace(namespace, key string) (int64, bool) {
+	return m.Client.GetInt64WithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetInt64(key string) (int64, bool) {
+	return m.Client.GetInt64(key)
+}
+
+func (m *Agollo) GetInt64WithNamespaceE(namespace, key string) (int64, error) {
+	return m.Client.GetInt64WithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetInt64E(key string) (int64, error) {
+	return m.Client.GetInt64E(key)
+}
+
+func (m *Agollo) GetInt64WithNamespaceOr(namespace, key string, def int64) int64 {
+	return m.Client.GetInt64WithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetInt64Or(key string, def int64) int64 {
+	return m.Client.GetInt64Or(key, def)
+}
+
+func (m *Agollo) GetUintWithNamespace(namespace, key string) (uint, bool) {
+	return m.Client.GetUintWithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetUint(key string) (uint, bool) {
+	return m.Client.GetUint(key)
+}
+
+func (m *Agollo) GetUintWithNamespaceE(namespace, key string) (uint, error) {
+	return m.Client.GetUintWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetUintE(key string) (uint, error) {
+	return m.Client.GetUintE(key)
+}
+
+func (m *Agollo) GetUintWithNamespaceOr(namespace, key string, def uint) uint {
+	return m.Client.GetUintWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetUintOr(key string, def uint) uint {
+	return m.Client.GetUintOr(key, def)
+}
+
+func (m *Agollo) GetFloat32WithNamespace(namespace, key string) (float32, bool) {
+	return m.Client.GetFloat32WithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetFloat32(key string) (float32, bool) {
+	return m.Client.GetFloat32(key)
+}
+
+func (m *Agollo) GetFloat32WithNamespaceE(namespace, key string) (float32, error) {
+	return m.Client.GetFloat32WithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetFloat32E(key string) (float32, error) {
+	return m.Client.GetFloat32E(key)
+}
+
+func (m *Agollo) GetFloat32WithNamespaceOr(namespace, key string, def float32) float32 {
+	return m.Client.GetFloat32WithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetFloat32Or(key string, def float32) float32 {
+	return m.Client.GetFloat32Or(key, def)
+}
+
 func (m *Agollo) GetFloat64WithNamespace(namespace, key string) (float64, bool) {
 	return m.Client.GetFloat64WithNamespace(namespace, key)
 }
@@ -90,6 +234,22 @@ func (m *Agollo) GetFloat64(key string) (float64, bool) {
 	return m.Client.GetFloat64(key)
 }
 
+func (m *Agollo) GetFloat64WithNamespaceE(namespace, key string) (float64, error) {
+	return m.Client.GetFloat64WithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetFloat64E(key string) (float64, error) {
+	return m.Client.GetFloat64E(key)
+}
+
+func (m *Agollo) GetFloat64WithNamespaceOr(namespace, key string, def float64) float64 {
+	return m.Client.GetFloat64WithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetFloat64Or(key string, def float64) float64 {
+	return m.Client.GetFloat64Or(key, def)
+}
+
 func (m *Agollo) GetBoolWithNamespace(namespace, key string) (bool, bool) {
 	return m.Client.GetBoolWithNamespace(namespace, key)
 }
@@ -98,10 +258,194 @@ func (m *Agollo) GetBool(key string) (bool, bool) {
 	return m.Client.GetBool(key)
 }
 
+func (m *Agollo) GetBoolWithNamespaceE(namespace, key string) (bool, error) {
+	return m.Client.GetBoolWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetBoolE(key string) (bool, error) {
+	return m.Client.GetBoolE(key)
+}
+
+func (m *Agollo) GetBoolWithNamespaceOr(namespace, key string, def bool) bool {
+	return m.Client.GetBoolWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetBoolOr(key string, def bool) bool {
+	return m.Client.GetBoolOr(key, def)
+}
+
+func (m *Agollo) GetDurationWithNamespace(namespace, key string) (time.Duration, bool) {
+	return m.Client.GetDurationWithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetDuration(key string) (time.Duration, bool) {
+	return m.Client.GetDuration(key)
+}
+
+func (m *Agollo) GetDurationWithNamespaceE(namespace, key string) (time.Duration, error) {
+	return m.Client.GetDurationWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetDurationE(key string) (time.Duration, error) {
+	return m.Client.GetDurationE(key)
+}
+
+func (m *Agollo) GetDurationWithNamespaceOr(namespace, key string, def time.Duration) time.Duration {
+	return m.Client.GetDurationWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetDurationOr(key string, def time.Duration) time.Duration {
+	return m.Client.GetDurationOr(key, def)
+}
+
+func (m *Agollo) GetTimeWithNamespace(namespace, key string) (time.Time, bool) {
+	return m.Client.GetTimeWithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetTime(key string) (time.Time, bool) {
+	return m.Client.GetTime(key)
+}
+
+func (m *Agollo) GetTimeWithNamespaceE(namespace, key string) (time.Time, error) {
+	return m.Client.GetTimeWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetTimeE(key string) (time.Time, error) {
+	return m.Client.GetTimeE(key)
+}
+
+func (m *Agollo) GetTimeWithNamespaceOr(namespace, key string, def time.Time) time.Time {
+	return m.Client.GetTimeWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetTimeOr(key string, def time.Time) time.Time {
+	return m.Client.GetTimeOr(key, def)
+}
+
+func (m *Agollo) GetSizeInBytesWithNamespace(namespace, key string) (uint64, bool) {
+	return m.Client.GetSizeInBytesWithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetSizeInBytes(key string) (uint64, bool) {
+	return m.Client.GetSizeInBytes(key)
+}
+
+func (m *Agollo) GetSizeInBytesWithNamespaceE(namespace, key string) (uint64, error) {
+	return m.Client.GetSizeInBytesWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetSizeInBytesE(key string) (uint64, error) {
+	return m.Client.GetSizeInBytesE(key)
+}
+
+func (m *Agollo) GetSizeInBytesWithNamespaceOr(namespace, key string, def uint64) uint64 {
+	return m.Client.GetSizeInBytesWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetSizeInBytesOr(key string, def uint64) uint64 {
+	return m.Client.GetSizeInBytesOr(key, def)
+}
+
+func (m *Agollo) GetIntSliceWithNamespace(namespace, key string) ([]int, bool) {
+	return m.Client.GetIntSliceWithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetIntSlice(key string) ([]int, bool) {
+	return m.Client.GetIntSlice(key)
+}
+
+func (m *Agollo) GetIntSliceWithNamespaceE(namespace, key string) ([]int, error) {
+	return m.Client.GetIntSliceWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetIntSliceE(key string) ([]int, error) {
+	return m.Client.GetIntSliceE(key)
+}
+
+func (m *Agollo) GetIntSliceWithNamespaceOr(namespace, key string, def []int) []int {
+	return m.Client.GetIntSliceWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetIntSliceOr(key string, def []int) []int {
+	return m.Client.GetIntSliceOr(key, def)
+}
+
+func (m *Agollo) GetStringSliceWithNamespace(namespace, key string) ([]string, bool) {
+	return m.Client.GetStringSliceWithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetStringSlice(key string) ([]string, bool) {
+	return m.Client.GetStringSlice(key)
+}
+
+func (m *Agollo) GetStringSliceWithNamespaceE(namespace, key string) ([]string, error) {
+	return m.Client.GetStringSliceWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetStringSliceE(key string) ([]string, error) {
+	return m.Client.GetStringSliceE(key)
+}
+
+func (m *Agollo) GetStringSliceWithNamespaceOr(namespace, key string, def []string) []string {
+	return m.Client.GetStringSliceWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetStringSliceOr(key string, def []string) []string {
+	return m.Client.GetStringSliceOr(key, def)
+}
+
+func (m *Agollo) GetStringMapStringWithNamespace(namespace, key string) (map[string]string, bool) {
+	return m.Client.GetStringMapStringWithNamespace(namespace, key)
+}
+
+func (m *Agollo) GetStringMapString(key string) (map[string]string, bool) {
+	return m.Client.GetStringMapString(key)
+}
+
+func (m *Agollo) GetStringMapStringWithNamespaceE(namespace, key string) (map[string]string, error) {
+	return m.Client.GetStringMapStringWithNamespaceE(namespace, key)
+}
+
+func (m *Agollo) GetStringMapStringE(key string) (map[string]string, error) {
+	return m.Client.GetStringMapStringE(key)
+}
+
+func (m *Agollo) GetStringMapStringWithNamespaceOr(namespace, key string, def map[string]string) map[string]string {
+	return m.Client.GetStringMapStringWithNamespaceOr(namespace, key, def)
+}
+
+func (m *Agollo) GetStringMapStringOr(key string, def map[string]string) map[string]string {
+	return m.Client.GetStringMapStringOr(key, def)
+}
+
 func (m *Agollo) GetNameSpaceContent(namespace string) (string, bool) {
 	return m.Client.GetNamespaceContent(namespace)
 }
 
+func (m *Agollo) SetChain(name string, namespaces ...string) {
+	m.Client.SetChain(name, namespaces...)
+}
+
+func (m *Agollo) Bind(namespace string, val interface{}) error {
+	return m.Client.Bind(namespace, val)
+}
+
+func (m *Agollo) BindAndWatch(namespace string, val interface{}) (*Binding, error) {
+	return m.Client.BindAndWatch(namespace, val)
+}
+
+func (m *Agollo) WatchKey(namespace, key string, fn func(old, new interface{})) (unsubscribe func()) {
+	return m.Client.WatchKey(namespace, key, fn)
+}
+
+func (m *Agollo) WatchPrefix(namespace, prefix string, fn KeyChangeFunc) (unsubscribe func()) {
+	return m.Client.WatchPrefix(namespace, prefix, fn)
+}
+
+func (m *Agollo) WatchGlob(namespace, pattern string, fn KeyChangeFunc) (unsubscribe func(), err error) {
+	return m.Client.WatchGlob(namespace, pattern, fn)
+}
+
 func (m *Agollo) GetAllKeys(namespace string) []string {
 	return m.Client.GetAllKeys(namespace)
 }
@@ -110,6 +454,10 @@ func (m *Agollo) GetReleaseKey(namespace string) (string, bool) {
 	return m.Client.GetReleaseKey(namespace)
 }
 
+func (m *Agollo) GetSnapshot(namespace string) *Snapshot {
+	return m.Client.GetSnapshot(namespace)
+}
+
 // Start agollo [Deprecated]
 func Start() error {
 	if defaultAgollo.Client == nil {
@@ -132,17 +480,63 @@ func StartWithConf(conf *Conf) error {
 	return defaultAgollo.StartWithConf(conf)
 }
 
-// Stop sync config
+// StartWithConfContext run agollo with Conf, ctx bounds requests made while preloading
+func StartWithConfContext(ctx context.Context, conf *Conf) error {
+	return defaultAgollo.StartWithConfContext(ctx, conf)
+}
+
+// Stop sync config, it waits for all observers without deadline, use
+// StopContext for a deadline
 func Stop() error {
 	return defaultAgollo.Stop()
 }
 
-// StartWatchUpdate starts an infinite loop reading changeEvent from update channel
-//   and calls HandleChangeEvent method of all observers
+// StopContext sync config, wait until background goroutines exit and delivered change events are handled, or ctx is done
+func StopContext(ctx context.Context) error {
+	return defaultAgollo.StopContext(ctx)
+}
+
+// GetPollerState return state of the long poller, for diagnostics
+func GetPollerState() PollerState {
+	return defaultAgollo.GetPollerState()
+}
+
+// Ready block until all namespaces have a remote or cached value, or ctx is done
+func Ready(ctx context.Context) error {
+	return defaultAgollo.Ready(ctx)
+}
+
+// WaitReady is like Ready, but with a timeout
+func WaitReady(timeout time.Duration) error {
+	return defaultAgollo.WaitReady(timeout)
+}
+
+// GetNamespaceStatus return status of namespace
+func GetNamespaceStatus(namespace string) (NamespaceStatus, bool) {
+	return defaultAgollo.GetNamespaceStatus(namespace)
+}
+
+// GetNamespaceStatuses return status of all namespaces, sorted by namespace
+func GetNamespaceStatuses() []NamespaceStatus {
+	return defaultAgollo.GetNamespaceStatuses()
+}
+
+// StartWatchUpdate starts a goroutine reading changeEvent from update channel
+//   and calls HandleChangeEvent method of all observers, until agollo stops
 func StartWatchUpdate() {
 	defaultAgollo.StartWatchUpdate()
 }
 
+// WaitDispatch block until delivered change events are handled by observers, or ctx is done
+func WaitDispatch(ctx context.Context) error {
+	return defaultAgollo.WaitDispatch(ctx)
+}
+
+// GetObserverStats return queue depth and delivery counters of all observers
+func GetObserverStats() []ObserverStats {
+	return defaultAgollo.GetObserverStats()
+}
+
 // RegisterObserver registers an observer that will be notified when change event happens
 func RegisterObserver(observer ChangeEventObserver) (recall func()) {
 	return defaultAgollo.RegisterObserver(observer)
@@ -153,45 +547,441 @@ func SubscribeToNamespaces(namespaces ...string) error {
 	return defaultAgollo.SubscribeToNamespaces(namespaces...)
 }
 
+// SubscribeToNamespacesContext is like SubscribeToNamespaces, ctx bounds the requests
+func SubscribeToNamespacesContext(ctx context.Context, namespaces ...string) error {
+	return defaultAgollo.SubscribeToNamespacesContext(ctx, namespaces...)
+}
+
+// GoOnline switch agollo started with Conf.Offline to apollo
+func GoOnline(ctx context.Context) error {
+	return defaultAgollo.GoOnline(ctx)
+}
+
 // GetStringWithNamespace get value from given namespace
 func GetStringWithNamespace(namespace, key string) (string, bool) {
 	return defaultAgollo.GetStringWithNamespace(namespace, key)
 }
 
-// GetString from default namespace
+// GetString get value from default namespace
 func GetString(key string) (string, bool) {
-	return GetStringWithNamespace(defaultNamespace, key)
+	return defaultAgollo.GetString(key)
 }
 
+// GetStringWithNamespaceE is like GetStringWithNamespace, but report why value is unavailable
+func GetStringWithNamespaceE(namespace, key string) (string, error) {
+	return defaultAgollo.GetStringWithNamespaceE(namespace, key)
+}
+
+// GetStringE is like GetString, but report why value is unavailable
+func GetStringE(key string) (string, error) {
+	return defaultAgollo.GetStringE(key)
+}
+
+// GetStringWithNamespaceOr is like GetStringWithNamespace, but return def if value is unavailable
+func GetStringWithNamespaceOr(namespace, key string, def string) string {
+	return defaultAgollo.GetStringWithNamespaceOr(namespace, key, def)
+}
+
+// GetStringOr is like GetString, but return def if value is unavailable
+func GetStringOr(key string, def string) string {
+	return defaultAgollo.GetStringOr(key, def)
+}
+
+// GetIntWithNamespace get value from given namespace
 func GetIntWithNamespace(namespace, key string) (int, bool) {
 	return defaultAgollo.GetIntWithNamespace(namespace, key)
 }
 
+// GetInt get value from default namespace
 func GetInt(key string) (int, bool) {
 	return defaultAgollo.GetInt(key)
 }
 
+// GetIntWithNamespaceE is like GetIntWithNamespace, but report why value is unavailable
+func GetIntWithNamespaceE(namespace, key string) (int, error) {
+	return defaultAgollo.GetIntWithNamespaceE(namespace, key)
+}
+
+// GetIntE is like GetInt, but report why value is unavailable
+func GetIntE(key string) (int, error) {
+	return defaultAgollo.GetIntE(key)
+}
+
+// GetIntWithNamespaceOr is like GetIntWithNamespace, but return def if value is unavailable
+func GetIntWithNamespaceOr(namespace, key string, def int) int {
+	return defaultAgollo.GetIntWithNamespaceOr(namespace, key, def)
+}
+
+// GetIntOr is like GetInt, but return def if value is unavailable
+func GetIntOr(key string, def int) int {
+	return defaultAgollo.GetIntOr(key, def)
+}
+
+// GetInt64WithNamespace get value from given namespace
+func GetInt64WithNamespace(namespace, key string) (int64, bool) {
+	return defaultAgollo.GetInt64WithNamespace(namespace, key)
+}
+
+// GetInt64 get value from default namespace
+func GetInt64(key string) (int64, bool) {
+	return defaultAgollo.GetInt64(key)
+}
+
+// GetInt64WithNamespaceE is like GetInt64WithNamespace, but report why value is unavailable
+func GetInt64WithNamespaceE(namespace, key string) (int64, error) {
+	return defaultAgollo.GetInt64WithNamespaceE(namespace, key)
+}
+
+// GetInt64E is like GetInt64, but report why value is unavailable
+func GetInt64E(key string) (int64, error) {
+	return defaultAgollo.GetInt64E(key)
+}
+
+// GetInt64WithNamespaceOr is like GetInt64WithNamespace, but return def if value is unavailable
+func GetInt64WithNamespaceOr(namespace, key string, def int64) int64 {
+	return defaultAgollo.GetInt64WithNamespaceOr(namespace, key, def)
+}
+
+// GetInt64Or is like GetInt64, but return def if value is unavailable
+func GetInt64Or(key string, def int64) int64 {
+	return defaultAgollo.GetInt64Or(key, def)
+}
+
+// GetUintWithNamespace get value from given namespace
+func GetUintWithNamespace(namespace, key string) (uint, bool) {
+	return defaultAgollo.GetUintWithNamespace(namespace, key)
+}
+
+// GetUint get value from default namespace
+func GetUint(key string) (uint, bool) {
+	return defaultAgollo.GetUint(key)
+}
+
+// GetUintWithNamespaceE is like GetUintWithNamespace, but report why value is unavailable
+func GetUintWithNamespaceE(namespace, key string) (uint, error) {
+	return defaultAgollo.GetUintWithNamespaceE(namespace, key)
+}
+
+// GetUintE is like GetUint, but report why value is unavailable
+func GetUintE(key string) (uint, error) {
+	return defaultAgollo.GetUintE(key)
+}
+
+// GetUintWithNamespaceOr is like GetUintWithNamespace, but return def if value is unavailable
+func GetUintWithNamespaceOr(namespace, key string, def uint) uint {
+	return defaultAgollo.GetUintWithNamespaceOr(namespace, key, def)
+}
+
+// GetUintOr is like GetUint, but return def if value is unavailable
+func GetUintOr(key string, def uint) uint {
+	return defaultAgollo.GetUintOr(key, def)
+}
+
+// GetFloat32WithNamespace get value from given namespace
+func GetFloat32WithNamespace(namespace, key string) (float32, bool) {
+	return defaultAgollo.GetFloat32WithNamespace(namespace, key)
+}
+
+// GetFloat32 get value from default namespace
+func GetFloat32(key string) (float32, bool) {
+	return defaultAgollo.GetFloat32(key)
+}
+
+// GetFloat32WithNamespaceE is like GetFloat32WithNamespace, but report why value is unavailable
+func GetFloat32WithNamespaceE(namespace, key string) (float32, error) {
+	return defaultAgollo.GetFloat32WithNamespaceE(namespace, key)
+}
+
+// GetFloat32E is like GetFloat32, but report why value is unavailable
+func GetFloat32E(key string) (float32, error) {
+	return defaultAgollo.GetFloat32E(key)
+}
+
+// GetFloat32WithNamespaceOr is like GetFloat32WithNamespace, but return def if value is unavailable
+func GetFloat32WithNamespaceOr(namespace, key string, def float32) float32 {
+	return defaultAgollo.GetFloat32WithNamespaceOr(namespace, key, def)
+}
+
+// GetFloat32Or is like GetFloat32, but return def if value is unavailable
+func GetFloat32Or(key string, def float32) float32 {
+	return defaultAgollo.GetFloat32Or(key, def)
+}
+
+// GetFloat64WithNamespace get value from given namespace
 func GetFloat64WithNamespace(namespace, key string) (float64, bool) {
 	return defaultAgollo.GetFloat64WithNamespace(namespace, key)
 }
 
+// GetFloat64 get value from default namespace
 func GetFloat64(key string) (float64, bool) {
 	return defaultAgollo.GetFloat64(key)
 }
 
+// GetFloat64WithNamespaceE is like GetFloat64WithNamespace, but report why value is unavailable
+func GetFloat64WithNamespaceE(namespace, key string) (float64, error) {
+	return defaultAgollo.GetFloat64WithNamespaceE(namespace, key)
+}
+
+// GetFloat64E is like GetFloat64, but report why value is unavailable
+func GetFloat64E(key string) (float64, error) {
+	return defaultAgollo.GetFloat64E(key)
+}
+
+// GetFloat64WithNamespaceOr is like GetFloat64WithNamespace, but return def if value is unavailable
+func GetFloat64WithNamespaceOr(namespace, key string, def float64) float64 {
+	return defaultAgollo.GetFloat64WithNamespaceOr(namespace, key, def)
+}
+
+// GetFloat64Or is like GetFloat64, but return def if value is unavailable
+func GetFloat64Or(key string, def float64) float64 {
+	return defaultAgollo.GetFloat64Or(key, def)
+}
+
+// GetBoolWithNamespace get value from given namespace
 func GetBoolWithNamespace(namespace, key string) (bool, bool) {
 	return defaultAgollo.GetBoolWithNamespace(namespace, key)
 }
 
+// GetBool get value from default namespace
 func GetBool(key string) (bool, bool) {
 	return defaultAgollo.GetBool(key)
 }
 
+// GetBoolWithNamespaceE is like GetBoolWithNamespace, but report why value is unavailable
+func GetBoolWithNamespaceE(namespace, key string) (bool, error) {
+	return defaultAgollo.GetBoolWithNamespaceE(namespace, key)
+}
+
+// GetBoolE is like GetBool, but report why value is unavailable
+func GetBoolE(key string) (bool, error) {
+	return defaultAgollo.GetBoolE(key)
+}
+
+// GetBoolWithNamespaceOr is like GetBoolWithNamespace, but return def if value is unavailable
+func GetBoolWithNamespaceOr(namespace, key string, def bool) bool {
+	return defaultAgollo.GetBoolWithNamespaceOr(namespace, key, def)
+}
+
+// GetBoolOr is like GetBool, but return def if value is unavailable
+func GetBoolOr(key string, def bool) bool {
+	return defaultAgollo.GetBoolOr(key, def)
+}
+
+// GetDurationWithNamespace get value from given namespace
+func GetDurationWithNamespace(namespace, key string) (time.Duration, bool) {
+	return defaultAgollo.GetDurationWithNamespace(namespace, key)
+}
+
+// GetDuration get value from default namespace
+func GetDuration(key string) (time.Duration, bool) {
+	return defaultAgollo.GetDuration(key)
+}
+
+// GetDurationWithNamespaceE is like GetDurationWithNamespace, but report why value is unavailable
+func GetDurationWithNamespaceE(namespace, key string) (time.Duration, error) {
+	return defaultAgollo.GetDurationWithNamespaceE(namespace, key)
+}
+
+// GetDurationE is like GetDuration, but report why value is unavailable
+func GetDurationE(key string) (time.Duration, error) {
+	return defaultAgollo.GetDurationE(key)
+}
+
+// GetDurationWithNamespaceOr is like GetDurationWithNamespace, but return def if value is unavailable
+func GetDurationWithNamespaceOr(namespace, key string, def time.Duration) time.Duration {
+	return defaultAgollo.GetDurationWithNamespaceOr(namespace, key, def)
+}
+
+// GetDurationOr is like GetDuration, but return def if value is unavailable
+func GetDurationOr(key string, def time.Duration) time.Duration {
+	return defaultAgollo.GetDurationOr(key, def)
+}
+
+// GetTimeWithNamespace get value from given namespace
+func GetTimeWithNamespace(namespace, key string) (time.Time, bool) {
+	return defaultAgollo.GetTimeWithNamespace(namespace, key)
+}
+
+// GetTime get value from default namespace
+func GetTime(key string) (time.Time, bool) {
+	return defaultAgollo.GetTime(key)
+}
+
+// GetTimeWithNamespaceE is like GetTimeWithNamespace, but report why value is unavailable
+func GetTimeWithNamespaceE(namespace, key string) (time.Time, error) {
+	return defaultAgollo.GetTimeWithNamespaceE(namespace, key)
+}
+
+// GetTimeE is like GetTime, but report why value is unavailable
+func GetTimeE(key string) (time.Time, error) {
+	return defaultAgollo.GetTimeE(key)
+}
+
+// GetTimeWithNamespaceOr is like GetTimeWithNamespace, but return def if value is unavailable
+func GetTimeWithNamespaceOr(namespace, key string, def time.Time) time.Time {
+	return defaultAgollo.GetTimeWithNamespaceOr(namespace, key, def)
+}
+
+// GetTimeOr is like GetTime, but return def if value is unavailable
+func GetTimeOr(key string, def time.Time) time.Time {
+	return defaultAgollo.GetTimeOr(key, def)
+}
+
+// GetSizeInBytesWithNamespace get value from given namespace
+func GetSizeInBytesWithNamespace(namespace, key string) (uint64, bool) {
+	return defaultAgollo.GetSizeInBytesWithNamespace(namespace, key)
+}
+
+// GetSizeInBytes get value from default namespace
+func GetSizeInBytes(key string) (uint64, bool) {
+	return defaultAgollo.GetSizeInBytes(key)
+}
+
+// GetSizeInBytesWithNamespaceE is like GetSizeInBytesWithNamespace, but report why value is unavailable
+func GetSizeInBytesWithNamespaceE(namespace, key string) (uint64, error) {
+	return defaultAgollo.GetSizeInBytesWithNamespaceE(namespace, key)
+}
+
+// GetSizeInBytesE is like GetSizeInBytes, but report why value is unavailable
+func GetSizeInBytesE(key string) (uint64, error) {
+	return defaultAgollo.GetSizeInBytesE(key)
+}
+
+// GetSizeInBytesWithNamespaceOr is like GetSizeInBytesWithNamespace, but return def if value is unavailable
+func GetSizeInBytesWithNamespaceOr(namespace, key string, def uint64) uint64 {
+	return defaultAgollo.GetSizeInBytesWithNamespaceOr(namespace, key, def)
+}
+
+// GetSizeInBytesOr is like GetSizeInBytes, but return def if value is unavailable
+func GetSizeInBytesOr(key string, def uint64) uint64 {
+	return defaultAgollo.GetSizeInBytesOr(key, def)
+}
+
+// GetIntSliceWithNamespace get value from given namespace
+func GetIntSliceWithNamespace(namespace, key string) ([]int, bool) {
+	return defaultAgollo.GetIntSliceWithNamespace(namespace, key)
+}
+
+// GetIntSlice get value from default namespace
+func GetIntSlice(key string) ([]int, bool) {
+	return defaultAgollo.GetIntSlice(key)
+}
+
+// GetIntSliceWithNamespaceE is like GetIntSliceWithNamespace, but report why value is unavailable
+func GetIntSliceWithNamespaceE(namespace, key string) ([]int, error) {
+	return defaultAgollo.GetIntSliceWithNamespaceE(namespace, key)
+}
+
+// GetIntSliceE is like GetIntSlice, but report why value is unavailable
+func GetIntSliceE(key string) ([]int, error) {
+	return defaultAgollo.GetIntSliceE(key)
+}
+
+// GetIntSliceWithNamespaceOr is like GetIntSliceWithNamespace, but return def if value is unavailable
+func GetIntSliceWithNamespaceOr(namespace, key string, def []int) []int {
+	return defaultAgollo.GetIntSliceWithNamespaceOr(namespace, key, def)
+}
+
+// GetIntSliceOr is like GetIntSlice, but return def if value is unavailable
+func GetIntSliceOr(key string, def []int) []int {
+	return defaultAgollo.GetIntSliceOr(key, def)
+}
+
+// GetStringSliceWithNamespace get value from given namespace
+func GetStringSliceWithNamespace(namespace, key string) ([]string, bool) {
+	return defaultAgollo.GetStringSliceWithNamespace(namespace, key)
+}
+
+// GetStringSlice get value from default namespace
+func GetStringSlice(key string) ([]string, bool) {
+	return defaultAgollo.GetStringSlice(key)
+}
+
+// GetStringSliceWithNamespaceE is like GetStringSliceWithNamespace, but report why value is unavailable
+func GetStringSliceWithNamespaceE(namespace, key string) ([]string, error) {
+	return defaultAgollo.GetStringSliceWithNamespaceE(namespace, key)
+}
+
+// GetStringSliceE is like GetStringSlice, but report why value is unavailable
+func GetStringSliceE(key string) ([]string, error) {
+	return defaultAgollo.GetStringSliceE(key)
+}
+
+// GetStringSliceWithNamespaceOr is like GetStringSliceWithNamespace, but return def if value is unavailable
+func GetStringSliceWithNamespaceOr(namespace, key string, def []string) []string {
+	return defaultAgollo.GetStringSliceWithNamespaceOr(namespace, key, def)
+}
+
+// GetStringSliceOr is like GetStringSlice, but return def if value is unavailable
+func GetStringSliceOr(key string, def []string) []string {
+	return defaultAgollo.GetStringSliceOr(key, def)
+}
+
+// GetStringMapStringWithNamespace get value from given namespace
+func GetStringMapStringWithNamespace(namespace, key string) (map[string]string, bool) {
+	return defaultAgollo.GetStringMapStringWithNamespace(namespace, key)
+}
+
+// GetStringMapString get value from default namespace
+func GetStringMapString(key string) (map[string]string, bool) {
+	return defaultAgollo.GetStringMapString(key)
+}
+
+// GetStringMapStringWithNamespaceE is like GetStringMapStringWithNamespace, but report why value is unavailable
+func GetStringMapStringWithNamespaceE(namespace, key string) (map[string]string, error) {
+	return defaultAgollo.GetStringMapStringWithNamespaceE(namespace, key)
+}
+
+// GetStringMapStringE is like GetStringMapString, but report why value is unavailable
+func GetStringMapStringE(key string) (map[string]string, error) {
+	return defaultAgollo.GetStringMapStringE(key)
+}
+
+// GetStringMapStringWithNamespaceOr is like GetStringMapStringWithNamespace, but return def if value is unavailable
+func GetStringMapStringWithNamespaceOr(namespace, key string, def map[string]string) map[string]string {
+	return defaultAgollo.GetStringMapStringWithNamespaceOr(namespace, key, def)
+}
+
+// GetStringMapStringOr is like GetStringMapString, but return def if value is unavailable
+func GetStringMapStringOr(key string, def map[string]string) map[string]string {
+	return defaultAgollo.GetStringMapStringOr(key, def)
+}
+
 // GetNamespaceContent get contents of namespace
 func GetNameSpaceContent(namespace string) (string, bool) {
 	return defaultAgollo.GetNameSpaceContent(namespace)
 }
 
+// SetChain define a lookup chain of namespaces, see Client.SetChain
+func SetChain(name string, namespaces ...string) {
+	defaultAgollo.SetChain(name, namespaces...)
+}
+
+// Bind decode config of namespace into val, see Client.Bind
+func Bind(namespace string, val interface{}) error {
+	return defaultAgollo.Bind(namespace, val)
+}
+
+// BindAndWatch bind config of namespace into val, and keep it updated, see Client.BindAndWatch
+func BindAndWatch(namespace string, val interface{}) (*Binding, error) {
+	return defaultAgollo.BindAndWatch(namespace, val)
+}
+
+// WatchKey call fn when key of namespace changes, see Client.WatchKey
+func WatchKey(namespace, key string, fn func(old, new interface{})) (unsubscribe func()) {
+	return defaultAgollo.WatchKey(namespace, key, fn)
+}
+
+// WatchPrefix call fn when keys of namespace with prefix change
+func WatchPrefix(namespace, prefix string, fn KeyChangeFunc) (unsubscribe func()) {
+	return defaultAgollo.WatchPrefix(namespace, prefix, fn)
+}
+
+// WatchGlob call fn when keys of namespace matching pattern change, see Client.WatchGlob
+func WatchGlob(namespace, pattern string, fn KeyChangeFunc) (unsubscribe func(), err error) {
+	return defaultAgollo.WatchGlob(namespace, pattern, fn)
+}
+
 // GetAllKeys return all config keys in given namespace
 func GetAllKeys(namespace string) []string {
 	return defaultAgollo.GetAllKeys(namespace)
@@ -202,6 +992,11 @@ func GetReleaseKey(namespace string) (string, bool) {
 	return defaultAgollo.GetReleaseKey(namespace)
 }
 
+// GetSnapshot return values of namespace at its current release
+func GetSnapshot(namespace string) *Snapshot {
+	return defaultAgollo.GetSnapshot(namespace)
+}
+
 func SetLogger(logger AgolloLogger) {
 	defaultLogger = logger
 }
@@ -1,8 +1,12 @@
 package agollo
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -19,7 +23,7 @@ func TestMain(m *testing.M) {
 
 func setup() {
 	go func() {
-		if err := mockserver.Run(); err != nil {
+		if err := mockserver.Run(); err != nil && err != http.ErrServerClosed {
 			log.Fatal(err)
 		}
 	}()
@@ -102,7 +106,7 @@ func TestGetString(t *testing.T) {
 	}
 
 	_ = SubscribeToNamespaces(anotherNamespace)
-	_ = defaultAgollo.Client.preload()
+	_ = defaultAgollo.Client.preload(context.Background())
 
 
 	cases := []struct{
@@ -149,7 +153,7 @@ func TestGetInt(t *testing.T) {
 	}
 
 	_ = SubscribeToNamespaces(anotherNamespace)
-	_ = defaultAgollo.Client.preload()
+	_ = defaultAgollo.Client.preload(context.Background())
 
 	cases := []struct{
 		namespace string
@@ -195,7 +199,7 @@ func TestGetBool(t *testing.T) {
 	}
 
 	_ = SubscribeToNamespaces(anotherNamespace)
-	_ = defaultAgollo.Client.preload()
+	_ = defaultAgollo.Client.preload(context.Background())
 
 	cases := []struct{
 		namespace string
@@ -241,7 +245,7 @@ func TestGetFloat64(t *testing.T) {
 	}
 
 	_ = SubscribeToNamespaces(anotherNamespace)
-	_ = defaultAgollo.Client.preload()
+	_ = defaultAgollo.Client.preload(context.Background())
 
 	cases := []struct{
 		namespace string
@@ -426,3 +430,93 @@ func TestAgolloStart(t *testing.T) {
 	//	return
 	//}
 }
+
+func TestTypedGetters(t *testing.T) {
+	mockserver.Set(defaultNamespace, "tk_int64", "9007199254740993")
+	mockserver.Set(defaultNamespace, "tk_uint", "7")
+	mockserver.Set(defaultNamespace, "tk_float32", "1.5")
+	mockserver.Set(defaultNamespace, "tk_duration", "1m30s")
+	mockserver.Set(defaultNamespace, "tk_time", "2021-03-04 05:06:07")
+	mockserver.Set(defaultNamespace, "tk_size", "10MB")
+	mockserver.Set(defaultNamespace, "tk_ints", "1, 2,3")
+	mockserver.Set(defaultNamespace, "tk_strs", "a,b")
+	mockserver.Set(defaultNamespace, "tk_map", "a=1,b=2")
+
+	if err := StartWithConf(defaultConf); err != nil {
+		t.Error(err)
+	}
+	_ = defaultAgollo.Client.preload(context.Background())
+
+	if v, ok := GetInt64("tk_int64"); !ok || v != 9007199254740993 {
+		t.Errorf("GetInt64 got:%v %v", v, ok)
+	}
+	if v, ok := GetUint("tk_uint"); !ok || v != 7 {
+		t.Errorf("GetUint got:%v %v", v, ok)
+	}
+	if v, ok := GetFloat32("tk_float32"); !ok || v != 1.5 {
+		t.Errorf("GetFloat32 got:%v %v", v, ok)
+	}
+	if v, ok := GetDuration("tk_duration"); !ok || v != time.Second*90 {
+		t.Errorf("GetDuration got:%v %v", v, ok)
+	}
+	if v, ok := GetTime("tk_time"); !ok || !v.Equal(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("GetTime got:%v %v", v, ok)
+	}
+	if v, ok := GetSizeInBytes("tk_size"); !ok || v != 10<<20 {
+		t.Errorf("GetSizeInBytes got:%v %v", v, ok)
+	}
+	if v, ok := GetIntSlice("tk_ints"); !ok || !reflect.DeepEqual(v, []int{1, 2, 3}) {
+		t.Errorf("GetIntSlice got:%v %v", v, ok)
+	}
+	if v, ok := GetStringSlice("tk_strs"); !ok || !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("GetStringSlice got:%v %v", v, ok)
+	}
+	if v, ok := GetStringMapString("tk_map"); !ok || !reflect.DeepEqual(v, map[string]string{"a": "1", "b": "2"}) {
+		t.Errorf("GetStringMapString got:%v %v", v, ok)
+	}
+	if _, ok := GetDuration("tk_strs"); ok {
+		t.Errorf("GetDuration should fail on bad value")
+	}
+	if _, ok := GetUint("missing"); ok {
+		t.Errorf("GetUint should fail on missing key")
+	}
+}
+
+func TestGetterFamilies(t *testing.T) {
+	mockserver.Set(defaultNamespace, "ek_int", "42")
+	mockserver.Set(defaultNamespace, "ek_bad", "forty-two")
+
+	if err := StartWithConf(defaultConf); err != nil {
+		t.Error(err)
+	}
+	_ = defaultAgollo.Client.preload(context.Background())
+
+	if v, err := GetIntE("ek_int"); err != nil || v != 42 {
+		t.Errorf("GetIntE got:%v %v", v, err)
+	}
+
+	_, err := GetIntE("ek_bad")
+	var getErr *GetError
+	if !errors.Is(err, ErrParse) || !errors.As(err, &getErr) {
+		t.Errorf("GetIntE should fail with parse error, got:%v", err)
+	} else if getErr.Namespace != defaultNamespace || getErr.Key != "ek_bad" || getErr.Value != "forty-two" {
+		t.Errorf("unexpected GetError:%+v", getErr)
+	}
+
+	if _, err := GetIntWithNamespaceE(anotherNamespace, "ek_int"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetIntWithNamespaceE should fail with key not found, got:%v", err)
+	}
+
+	if v := GetIntOr("ek_int", 1); v != 42 {
+		t.Errorf("GetIntOr expected:42 got:%v", v)
+	}
+	if v := GetIntOr("ek_bad", 1); v != 1 {
+		t.Errorf("GetIntOr expected:1 got:%v", v)
+	}
+	if v := GetDurationWithNamespaceOr(nonExistNamespace, "ek_int", time.Second); v != time.Second {
+		t.Errorf("GetDurationWithNamespaceOr expected:1s got:%v", v)
+	}
+	if v := GetStringSliceOr("missing", []string{"a"}); !reflect.DeepEqual(v, []string{"a"}) {
+		t.Errorf("GetStringSliceOr expected:[a] got:%v", v)
+	}
+}
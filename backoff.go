@@ -0,0 +1,142 @@
+package agollo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BackoffPolicy decide how long poller waits before next poll after failures
+type BackoffPolicy struct {
+	// InitialDelay is the delay after the first failure
+	InitialDelay time.Duration `json:"initialDelay,omitempty"`
+	// MaxDelay caps the delay
+	MaxDelay time.Duration `json:"maxDelay,omitempty"`
+	// Multiplier grows the delay after each consecutive failure
+	Multiplier float64 `json:"multiplier,omitempty"`
+	// Jitter picks a random delay in [0, delay) if true, known as full jitter
+	Jitter bool `json:"jitter,omitempty"`
+}
+
+// UnmarshalJSON accept delays as duration strings, e.g. "1s", or numbers of
+// nanoseconds
+func (p *BackoffPolicy) UnmarshalJSON(data []byte) error {
+	type policy BackoffPolicy
+	var aux struct {
+		*policy
+		InitialDelay interface{} `json:"initialDelay,omitempty"`
+		MaxDelay     interface{} `json:"maxDelay,omitempty"`
+	}
+	aux.policy = (*policy)(p)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, d := range []struct {
+		name string
+		val  interface{}
+		ret  *time.Duration
+	}{
+		{"initialDelay", aux.InitialDelay, &p.InitialDelay},
+		{"maxDelay", aux.MaxDelay, &p.MaxDelay},
+	} {
+		if d.val == nil {
+			continue
+		}
+		delay, err := toDuration(d.val)
+		if err != nil {
+			return fmt.Errorf("%s: %w", d.name, err)
+		}
+		*d.ret = delay
+	}
+	return nil
+}
+
+// DefaultBackoffPolicy is used when Conf.Backoff is nil
+var DefaultBackoffPolicy = BackoffPolicy{
+	InitialDelay: longPollInterval,
+	MaxDelay:     time.Minute * 2,
+	Multiplier:   2,
+	Jitter:       true,
+}
+
+// PollerState is a snapshot of poller, for diagnostics
+type PollerState struct {
+	// ConsecutiveFailures is the count of failed polls since last success
+	ConsecutiveFailures int
+	// LastError is the error of last poll, nil if succeeded
+	LastError error
+	// LastPollAt is when last poll finished
+	LastPollAt time.Time
+	// NextDelay is how long poller waits before next poll
+	NextDelay time.Duration
+}
+
+// backoff track consecutive failures and calculate delay for next poll
+type backoff struct {
+	policy   BackoffPolicy
+	interval time.Duration
+
+	lock  sync.Mutex
+	rand  *rand.Rand
+	state PollerState
+}
+
+func newBackoff(policy *BackoffPolicy, interval time.Duration) *backoff {
+	b := &backoff{
+		policy:   DefaultBackoffPolicy,
+		interval: interval,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if policy != nil {
+		b.policy = *policy
+	}
+	if b.policy.InitialDelay <= 0 {
+		b.policy.InitialDelay = interval
+	}
+	if b.policy.MaxDelay < b.policy.InitialDelay {
+		b.policy.MaxDelay = b.policy.InitialDelay
+	}
+	if b.policy.Multiplier < 1 {
+		b.policy.Multiplier = 1
+	}
+	b.state.NextDelay = interval
+	return b
+}
+
+// next record result of a poll and return delay before next poll, interval
+// is used after success
+func (b *backoff) next(err error) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.state.LastError = err
+	b.state.LastPollAt = time.Now()
+
+	if err == nil {
+		b.state.ConsecutiveFailures = 0
+		b.state.NextDelay = b.interval
+		return b.state.NextDelay
+	}
+
+	b.state.ConsecutiveFailures++
+	delay := float64(b.policy.InitialDelay) * math.Pow(b.policy.Multiplier, float64(b.state.ConsecutiveFailures-1))
+	if delay > float64(b.policy.MaxDelay) {
+		delay = float64(b.policy.MaxDelay)
+	}
+	if b.policy.Jitter {
+		delay = b.rand.Float64() * delay
+	}
+	b.state.NextDelay = time.Duration(delay)
+	return b.state.NextDelay
+}
+
+func (b *backoff) getState() PollerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state
+}
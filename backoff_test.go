@@ -0,0 +1,82 @@
+package agollo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(&BackoffPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Second * 5,
+		Multiplier:   2,
+	}, longPollInterval)
+
+	if state := b.getState(); state.NextDelay != longPollInterval || state.ConsecutiveFailures != 0 {
+		t.Errorf("unexpected initial state:%+v", state)
+	}
+
+	err := errors.New("failed")
+	for i, expected := range []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5} {
+		if delay := b.next(err); delay != expected {
+			t.Errorf("failure %d: delay expected:%v got:%v", i+1, expected, delay)
+		}
+	}
+
+	state := b.getState()
+	if state.ConsecutiveFailures != 5 || state.LastError != err || state.LastPollAt.IsZero() || state.NextDelay != time.Second*5 {
+		t.Errorf("unexpected state:%+v", state)
+	}
+
+	if delay := b.next(nil); delay != longPollInterval {
+		t.Errorf("delay should reset to interval after success, got:%v", delay)
+	}
+	if state := b.getState(); state.ConsecutiveFailures != 0 || state.LastError != nil {
+		t.Errorf("state should reset after success, got:%+v", state)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := newBackoff(&BackoffPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Second * 8,
+		Multiplier:   2,
+		Jitter:       true,
+	}, longPollInterval)
+
+	err := errors.New("failed")
+	for i := 0; i < 10; i++ {
+		if delay := b.next(err); delay < 0 || delay >= time.Second*8 {
+			t.Errorf("delay should be in [0, 8s), got:%v", delay)
+		}
+	}
+}
+
+func TestBackoffDefault(t *testing.T) {
+	b := newBackoff(nil, longPollInterval)
+	if b.policy != DefaultBackoffPolicy {
+		t.Errorf("default policy should be used, got:%+v", b.policy)
+	}
+
+	b = newBackoff(&BackoffPolicy{}, longPollInterval)
+	if b.policy.InitialDelay != longPollInterval || b.policy.MaxDelay != longPollInterval || b.policy.Multiplier != 1 {
+		t.Errorf("zero policy should be fixed, got:%+v", b.policy)
+	}
+}
+
+func TestBackoffPolicyUnmarshalJSON(t *testing.T) {
+	var policy BackoffPolicy
+	if err := json.Unmarshal([]byte(`{"initialDelay":"1s","maxDelay":120000000000,"multiplier":2,"jitter":true}`), &policy); err != nil {
+		t.Fatal(err)
+	}
+	expected := BackoffPolicy{InitialDelay: time.Second, MaxDelay: time.Minute * 2, Multiplier: 2, Jitter: true}
+	if policy != expected {
+		t.Errorf("unexpected policy:%+v", policy)
+	}
+
+	if err := json.Unmarshal([]byte(`{"initialDelay":"soon"}`), &policy); err == nil {
+		t.Errorf("invalid delay should fail")
+	}
+}
@@ -0,0 +1,330 @@
+package agollo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const bindTag = "apollo"
+
+// Bind decode config of namespace into val, val should be a pointer to struct.
+//
+// Fields are bound to keys by tag like `apollo:"key,default=value"`, field
+// name is used if tag is absent, and `apollo:"-"` skips the field. Keys are
+// matched case-insensitively if no exact match is found. Nested structs use
+// their key as prefix, e.g. field Host of a struct tagged `apollo:"db"` is
+// bound to "db.host". Slices are bound to lists of file namespaces or comma
+// separated strings, maps are bound to sub trees or keys with the prefix.
+func (c *Client) Bind(namespace string, val interface{}) error {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("val should be a non-nil pointer to struct, got %T", val)
+	}
+
+	b := &binder{kv: c.getValues(namespace)}
+	_, err := b.bindStruct(rv.Elem(), "")
+	return err
+}
+
+// Binding hold the latest config decoded by BindAndWatch
+type Binding struct {
+	namespace string
+	typ       reflect.Type
+	value     atomic.Value
+	client    *Client
+}
+
+// Load return the latest decoded config, it's a pointer of the same type as
+// passed to BindAndWatch, it should be treated as read only
+func (b *Binding) Load() interface{} {
+	return b.value.Load()
+}
+
+// Close stop watching updates
+func (b *Binding) Close() {
+	b.client.recallObserver(b)
+}
+
+// HandleChangeEvent decode a fresh copy if namespace changes
+func (b *Binding) HandleChangeEvent(ce *ChangeEvent) {
+	if ce.Namespace != b.namespace {
+		return
+	}
+
+	val := reflect.New(b.typ).Interface()
+	if err := b.client.Bind(b.namespace, val); err != nil {
+		defaultLogger.Printf("module:agollo method:Binding.HandleChangeEvent namespace:%s err:%v", b.namespace, err)
+		return
+	}
+	b.value.Store(val)
+}
+
+// BindAndWatch bind config of namespace into val like Bind, then decode a
+// fresh copy whenever namespace changes, and swap it into the returned
+// Binding atomically, val itself is never modified after BindAndWatch returns.
+// Change events are delivered by StartWatchUpdate.
+func (c *Client) BindAndWatch(namespace string, val interface{}) (*Binding, error) {
+	if err := c.Bind(namespace, val); err != nil {
+		return nil, err
+	}
+
+	b := &Binding{
+		namespace: namespace,
+		typ:       reflect.TypeOf(val).Elem(),
+		client:    c,
+	}
+	b.value.Store(val)
+	c.registerObserver(b)
+	return b, nil
+}
+
+type binder struct {
+	kv map[string]interface{}
+}
+
+func (b *binder) lookup(key string) (interface{}, bool) {
+	if v, ok := b.kv[key]; ok {
+		return v, true
+	}
+	for k, v := range b.kv {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// withPrefix return keys with prefix, and the rest part of them
+func (b *binder) withPrefix(prefix string) map[string]string {
+	ret := map[string]string{}
+	for k := range b.kv {
+		if len(k) > len(prefix) && strings.EqualFold(k[:len(prefix)], prefix) {
+			ret[k[len(prefix):]] = k
+		}
+	}
+	return ret
+}
+
+// parseTag return key and default value of field
+func parseTag(field reflect.StructField) (key, def string, hasDef bool) {
+	tag := field.Tag.Get(bindTag)
+	key = tag
+	if i := strings.Index(tag, ","); i >= 0 {
+		key = tag[:i]
+		if opt := tag[i+1:]; strings.HasPrefix(opt, "default=") {
+			def, hasDef = strings.TrimPrefix(opt, "default="), true
+		}
+	}
+	if key == "" {
+		key = field.Name
+	}
+	return
+}
+
+func (b *binder) bindStruct(v reflect.Value, prefix string) (bool, error) {
+	var found bool
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		embedded := field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(bindTag) == ""
+		if (field.PkgPath != "" && !embedded) || field.Tag.Get(bindTag) == "-" {
+			continue
+		}
+
+		var ok bool
+		var err error
+		if embedded {
+			ok, err = b.bindStruct(v.Field(i), prefix)
+		} else {
+			key, def, hasDef := parseTag(field)
+			ok, err = b.bindValue(v.Field(i), prefix+key, def, hasDef)
+		}
+		if err != nil {
+			return false, err
+		}
+		found = found || ok
+	}
+	return found, nil
+}
+
+func (b *binder) bindValue(v reflect.Value, key, def string, hasDef bool) (bool, error) {
+	typ := v.Type()
+
+	switch {
+	case typ.Kind() == reflect.Ptr:
+		elem := reflect.New(typ.Elem())
+		found, err := b.bindValue(elem.Elem(), key, def, hasDef)
+		if found {
+			v.Set(elem)
+		}
+		return found, err
+	case typ == durationType, typ == timeType:
+		return b.bindScalar(v, key, def, hasDef)
+	case typ.Kind() == reflect.Struct:
+		return b.bindStruct(v, key+".")
+	case typ.Kind() == reflect.Slice:
+		return b.bindSlice(v, key, def, hasDef)
+	case typ.Kind() == reflect.Map:
+		return b.bindMap(v, key, def, hasDef)
+	}
+	return b.bindScalar(v, key, def, hasDef)
+}
+
+// bindScalar bind value of key or default value, return whether key is found
+func (b *binder) bindScalar(v reflect.Value, key, def string, hasDef bool) (bool, error) {
+	var raw interface{} = def
+	val, found := b.lookup(key)
+	if found {
+		raw = val
+	} else if !hasDef {
+		return false, nil
+	}
+
+	cv, err := convertScalar(raw, v.Type())
+	if err != nil {
+		return false, fmt.Errorf("key %s: %v", key, err)
+	}
+	v.Set(cv)
+	return found, nil
+}
+
+func (b *binder) bindSlice(v reflect.Value, key, def string, hasDef bool) (bool, error) {
+	var raw interface{} = def
+	val, found := b.lookup(key)
+	if found {
+		raw = val
+	} else if !hasDef {
+		return false, nil
+	}
+
+	var items []interface{}
+	switch val := raw.(type) {
+	case []interface{}:
+		items = val
+	case string:
+		for _, item := range splitList(val) {
+			items = append(items, item)
+		}
+	default:
+		items = []interface{}{val}
+	}
+
+	ret := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := b.bindElem(ret.Index(i), item, key+"["+strconv.Itoa(i)+"]"); err != nil {
+			return false, err
+		}
+	}
+	v.Set(ret)
+	return found, nil
+}
+
+// bindElem bind item of slice or map to v, structs are bound by key
+func (b *binder) bindElem(v reflect.Value, item interface{}, key string) error {
+	typ := v.Type()
+	switch {
+	case typ.Kind() == reflect.Ptr:
+		elem := reflect.New(typ.Elem())
+		if err := b.bindElem(elem.Elem(), item, key); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case typ.Kind() == reflect.Struct && typ != timeType:
+		_, err := b.bindStruct(v, key+".")
+		return err
+	}
+
+	cv, err := convertScalar(item, typ)
+	if err != nil {
+		return fmt.Errorf("key %s: %v", key, err)
+	}
+	v.Set(cv)
+	return nil
+}
+
+func (b *binder) bindMap(v reflect.Value, key, def string, hasDef bool) (bool, error) {
+	typ := v.Type()
+	if typ.Key().Kind() != reflect.String {
+		return false, fmt.Errorf("key %s: unsupported map key type %s", key, typ.Key())
+	}
+
+	// collect sub keys, from sub tree of file namespaces, or keys with prefix
+	values := map[string]interface{}{}
+	raw, ok := b.lookup(key)
+	if tree, isTree := raw.(map[string]interface{}); ok && isTree {
+		for k, item := range tree {
+			values[k] = item
+		}
+	} else if s, isStr := raw.(string); ok && isStr {
+		return b.bindMapString(v, key, s)
+	} else {
+		for sub, k := range b.withPrefix(key + ".") {
+			values[sub] = b.kv[k]
+		}
+	}
+
+	if len(values) == 0 {
+		if hasDef {
+			_, err := b.bindMapString(v, key, def)
+			return false, err
+		}
+		return false, nil
+	}
+
+	elemTyp := typ.Elem()
+	structTyp := elemTyp
+	if structTyp.Kind() == reflect.Ptr {
+		structTyp = structTyp.Elem()
+	}
+	ret := reflect.MakeMap(typ)
+	if structTyp.Kind() == reflect.Struct && structTyp != timeType {
+		// group keys by their first segment, e.g. "a.host" and "a.port" by "a"
+		groups := map[string]bool{}
+		for sub := range values {
+			groups[strings.SplitN(sub, ".", 2)[0]] = true
+		}
+		for group := range groups {
+			elem := reflect.New(elemTyp).Elem()
+			if err := b.bindElem(elem, nil, key+"."+group); err != nil {
+				return false, err
+			}
+			ret.SetMapIndex(reflect.ValueOf(group).Convert(typ.Key()), elem)
+		}
+		v.Set(ret)
+		return true, nil
+	}
+
+	for sub, item := range values {
+		elem := reflect.New(elemTyp).Elem()
+		if err := b.bindElem(elem, item, key+"."+sub); err != nil {
+			return false, err
+		}
+		ret.SetMapIndex(reflect.ValueOf(sub).Convert(typ.Key()), elem)
+	}
+	v.Set(ret)
+	return true, nil
+}
+
+// bindMapString bind string like "k1=v1,k2=v2" to map
+func (b *binder) bindMapString(v reflect.Value, key, s string) (bool, error) {
+	typ := v.Type()
+	kv, err := splitMap(s)
+	if err != nil {
+		return false, fmt.Errorf("key %s: %v", key, err)
+	}
+
+	ret := reflect.MakeMap(typ)
+	for k, item := range kv {
+		cv, err := convertScalar(item, typ.Elem())
+		if err != nil {
+			return false, fmt.Errorf("key %s: %v", key, err)
+		}
+		ret.SetMapIndex(reflect.ValueOf(k).Convert(typ.Key()), cv)
+	}
+	v.Set(ret)
+	return true, nil
+}
@@ -0,0 +1,189 @@
+package agollo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type bindDB struct {
+	Host    string        `apollo:"host"`
+	Port    int           `apollo:"port,default=3306"`
+	Timeout time.Duration `apollo:"timeout,default=1s"`
+}
+
+type bindConfig struct {
+	Name    string            `apollo:"name"`
+	Debug   bool              `apollo:"debug"`
+	Ratio   float64           `apollo:"ratio,default=0.5"`
+	MaxConn uint32            `apollo:"maxConns"`
+	Tags    []string          `apollo:"tags"`
+	Ports   []int             `apollo:"ports,default=80,443"`
+	Labels  map[string]string `apollo:"labels"`
+	DB      bindDB            `apollo:"db"`
+	Backup  *bindDB           `apollo:"backup"`
+	Missing *bindDB           `apollo:"missing"`
+	Ignored string            `apollo:"-"`
+	Level   string
+}
+
+func TestBindKV(t *testing.T) {
+	c := NewClient(&Conf{AppID: "BindApp", CacheDir: "/tmp/agollo"})
+	c.handleResult(&result{
+		NamespaceName: defaultNamespace,
+		Configurations: map[string]interface{}{
+			"name":        "agollo",
+			"debug":       "true",
+			"maxConns":    "100",
+			"tags":        "a, b",
+			"labels.env":  "dev",
+			"labels.zone": "z1",
+			"db.host":     "localhost",
+			"db.timeout":  "2s",
+			"backup.host": "backup",
+			"Ignored":     "x",
+			"level":       "info",
+		},
+	})
+
+	var cfg bindConfig
+	if err := c.Bind(defaultNamespace, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := bindConfig{
+		Name:    "agollo",
+		Debug:   true,
+		Ratio:   0.5,
+		MaxConn: 100,
+		Tags:    []string{"a", "b"},
+		Ports:   []int{80, 443},
+		Labels:  map[string]string{"env": "dev", "zone": "z1"},
+		DB:      bindDB{Host: "localhost", Port: 3306, Timeout: time.Second * 2},
+		Backup:  &bindDB{Host: "backup", Port: 3306, Timeout: time.Second},
+		Level:   "info",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected:%+v got:%+v", expected, cfg)
+	}
+}
+
+func TestBindYAML(t *testing.T) {
+	c := NewClient(&Conf{AppID: "BindApp", CacheDir: "/tmp/agollo"})
+	c.handleResult(&result{
+		NamespaceName: "service.yaml",
+		Configurations: map[string]interface{}{"content": `
+name: agollo
+maxConns: 100
+tags: [a, b]
+ports: [8080, 8081]
+labels:
+  env: dev
+db:
+  host: localhost
+  port: 3307
+replicas:
+  - host: r1
+  - host: r2
+shards:
+  s1:
+    host: h1
+`},
+	})
+
+	var cfg struct {
+		bindConfig
+		Replicas    []bindDB           `apollo:"replicas"`
+		Shards      map[string]bindDB  `apollo:"shards"`
+		ReplicaPtrs []*bindDB          `apollo:"replicas"`
+		ShardPtrs   map[string]*bindDB `apollo:"shards"`
+		PortPtrs    []*int             `apollo:"ports"`
+		LabelPtrs   map[string]*string `apollo:"labels"`
+	}
+	if err := c.Bind("service.yaml", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Name != "agollo" || cfg.MaxConn != 100 || !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected config:%+v", cfg)
+	}
+	if cfg.Labels["env"] != "dev" || cfg.DB.Host != "localhost" || cfg.DB.Port != 3307 {
+		t.Errorf("unexpected config:%+v", cfg)
+	}
+	if len(cfg.Replicas) != 2 || cfg.Replicas[1].Host != "r2" || cfg.Replicas[1].Port != 3306 {
+		t.Errorf("unexpected replicas:%+v", cfg.Replicas)
+	}
+	if cfg.Shards["s1"].Host != "h1" {
+		t.Errorf("unexpected shards:%+v", cfg.Shards)
+	}
+
+	// pointer elements are bound like values
+	if len(cfg.ReplicaPtrs) != 2 || cfg.ReplicaPtrs[1] == nil || *cfg.ReplicaPtrs[1] != cfg.Replicas[1] {
+		t.Errorf("unexpected replica pointers:%+v", cfg.ReplicaPtrs)
+	}
+	if s1 := cfg.ShardPtrs["s1"]; s1 == nil || *s1 != cfg.Shards["s1"] {
+		t.Errorf("unexpected shard pointers:%+v", cfg.ShardPtrs)
+	}
+	if len(cfg.PortPtrs) != 2 || *cfg.PortPtrs[0] != 8080 || *cfg.PortPtrs[1] != 8081 {
+		t.Errorf("unexpected port pointers:%+v", cfg.PortPtrs)
+	}
+	if env := cfg.LabelPtrs["env"]; env == nil || *env != "dev" {
+		t.Errorf("unexpected label pointers:%+v", cfg.LabelPtrs)
+	}
+}
+
+func TestBindError(t *testing.T) {
+	c := NewClient(&Conf{AppID: "BindApp", CacheDir: "/tmp/agollo"})
+	c.handleResult(&result{
+		NamespaceName:  defaultNamespace,
+		Configurations: map[string]interface{}{"db.port": "abc"},
+	})
+
+	var cfg bindConfig
+	if err := c.Bind(defaultNamespace, &cfg); err == nil {
+		t.Errorf("bad value should fail")
+	}
+
+	if err := c.Bind(defaultNamespace, cfg); err == nil {
+		t.Errorf("non-pointer should fail")
+	}
+}
+
+func TestBindAndWatch(t *testing.T) {
+	c := NewClient(&Conf{AppID: "BindApp", CacheDir: "/tmp/agollo"})
+	c.handleResult(&result{
+		NamespaceName:  defaultNamespace,
+		Configurations: map[string]interface{}{"name": "v1"},
+	})
+
+	var cfg bindConfig
+	b, err := c.BindAndWatch(defaultNamespace, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	if b.Load().(*bindConfig).Name != "v1" {
+		t.Errorf("initial config should be loaded")
+	}
+
+	ce := c.handleResult(&result{
+		NamespaceName:  defaultNamespace,
+		Configurations: map[string]interface{}{"name": "v2"},
+	})
+	for _, ob := range c.getObservers() {
+		ob.HandleChangeEvent(ce)
+	}
+
+	if b.Load().(*bindConfig).Name != "v2" {
+		t.Errorf("config should be reloaded, got:%+v", b.Load())
+	}
+	if cfg.Name != "v1" {
+		t.Errorf("val passed in should not be modified")
+	}
+
+	b.Close()
+	if len(c.getObservers()) != 0 {
+		t.Errorf("binding should be unregistered after close")
+	}
+}
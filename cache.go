@@ -1,9 +1,8 @@
 package agollo
 
 import (
-	"encoding/gob"
-	"os"
 	"sync"
+	"sync/atomic"
 )
 
 type namespaceCache struct {
@@ -30,91 +29,93 @@ func (n *namespaceCache) mustGetCache(namespace string) *cache {
 	return cache
 }
 
-func (n *namespaceCache) drain() {
+// getCache return cache of namespace without creating it
+func (n *namespaceCache) getCache(namespace string) (*cache, bool) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
-	for namespace := range n.caches {
-		delete(n.caches, namespace)
-	}
+	ret, ok := n.caches[namespace]
+	return ret, ok
 }
 
-func (n *namespaceCache) dump(name string) error {
+func (n *namespaceCache) namespaces() []string {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
-	var dumps = make(map[string]map[string]interface{})
-
-	for namespace, cache := range n.caches {
-		dumps[namespace] = cache.dump()
-	}
-
-	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
-	if err != nil {
-		return err
+	var namespaces []string
+	for namespace := range n.caches {
+		namespaces = append(namespaces, namespace)
 	}
-	defer f.Close()
+	return namespaces
+}
 
-	return gob.NewEncoder(f).Encode(&dumps)
+// cache hold values of a namespace in an immutable map, updates replace the
+// map as a whole, so readers never see half of an update
+type cache struct {
+	// lock serialize writers
+	lock sync.Mutex
+	data atomic.Value // *cacheData
 }
 
-func (n *namespaceCache) load(name string) error {
-	n.drain()
+type cacheData struct {
+	values     map[string]interface{}
+	releaseKey string
+	released   bool
+}
 
-	f, err := os.OpenFile(name, os.O_RDONLY, 0755)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+func newCache() *cache {
+	c := &cache{}
+	c.data.Store(&cacheData{values: map[string]interface{}{}})
+	return c
+}
 
-	var dumps = make(map[string]map[string]interface{})
+func (c *cache) load() *cacheData {
+	return c.data.Load().(*cacheData)
+}
 
-	if err := gob.NewDecoder(f).Decode(&dumps); err != nil {
-		return err
+func (c *cache) get(key string) (interface{}, bool) {
+	if val, ok := c.load().values[key]; ok {
+		return val, true
 	}
+	return "", false
+}
 
-	for namespace, kv := range dumps {
-		cache := n.mustGetCache(namespace)
-		for k, v := range kv {
-			cache.set(k, v)
-		}
-	}
+// replace swap in values of a release, values must not be modified later
+func (c *cache) replace(values map[string]interface{}, releaseKey string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	return nil
+	c.data.Store(&cacheData{values: values, releaseKey: releaseKey, released: true})
 }
 
-type cache struct {
-	kv sync.Map
-}
+// reset swap in values not of a release, e.g. loaded from local files
+func (c *cache) reset(values map[string]interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-func newCache() *cache {
-	return &cache{
-		kv: sync.Map{},
-	}
+	c.data.Store(&cacheData{values: values})
 }
 
-func (c *cache) set(key string, val interface{}) {
-	c.kv.Store(key, val)
+// values return current values, which must not be modified
+func (c *cache) values() map[string]interface{} {
+	return c.load().values
 }
 
-func (c *cache) get(key string) (interface{}, bool) {
-	if val, ok := c.kv.Load(key); ok {
-		return val, true
-	}
-	return "", false
+func (c *cache) getReleaseKey() (string, bool) {
+	data := c.load()
+	return data.releaseKey, data.released
 }
 
-func (c *cache) delete(key string) {
-	c.kv.Delete(key)
+func (c *cache) snapshot(namespace string) *Snapshot {
+	data := c.load()
+	return newSnapshot(namespace, data.releaseKey, data.values)
 }
 
 func (c *cache) dump() map[string]interface{} {
-	var ret = make(map[string]interface{})
-	c.kv.Range(func(key, val interface{}) bool {
-		k, _ := key.(string)
-		ret[k] = val
-
-		return true
-	})
+	values := c.values()
+	var ret = make(map[string]interface{}, len(values))
+	for k, v := range values {
+		ret[k] = v
+	}
 	return ret
 }
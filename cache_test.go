@@ -1,66 +1,167 @@
 package agollo
 
 import (
-	"io/ioutil"
-	"os"
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 )
 
 func TestCache(t *testing.T) {
 	cache := newCache()
 
-	cache.set("key", "val")
+	cache.replace(map[string]interface{}{"key": "val"}, "r1")
 	if val, ok := cache.get("key"); !ok || val != "val" {
 		t.FailNow()
 	}
+	if key, ok := cache.getReleaseKey(); !ok || key != "r1" {
+		t.FailNow()
+	}
 
-	cache.set("key", "val2")
+	cache.reset(map[string]interface{}{"key": "val2"})
 	if val, ok := cache.get("key"); !ok || val != "val2" {
 		t.FailNow()
 	}
+	if _, ok := cache.getReleaseKey(); ok {
+		t.FailNow()
+	}
 
 	kv := cache.dump()
 	if len(kv) != 1 || kv["key"] != "val2" {
 		t.FailNow()
 	}
 
-	cache.delete("key")
+	cache.reset(map[string]interface{}{})
 	if _, ok := cache.get("key"); ok {
 		t.FailNow()
 	}
 }
 
-func TestCacheDump(t *testing.T) {
-	var caches = newNamespaceCahce()
-	defer caches.drain()
-	caches.mustGetCache("namespace").set("key", "val")
-
-	f, err := ioutil.TempFile(".", "agollo")
-	if err != nil {
-		t.Error(err)
+func TestSnapshot(t *testing.T) {
+	c := NewClient(&Conf{AppID: "SnapshotApp", CacheDir: "/tmp/agollo"})
+	if _, ok := c.GetReleaseKey("db"); ok {
+		t.Errorf("unknown namespace should not have release key")
 	}
-	f.Close()
-	defer os.Remove(f.Name())
 
-	if err := caches.dump(f.Name()); err != nil {
-		t.Error(err)
-	}
+	c.handleResult(&result{NamespaceName: "db", ReleaseKey: "r1", Configurations: map[string]interface{}{"host": "h1", "port": "1"}})
+	s1 := c.GetSnapshot("db")
+	c.handleResult(&result{NamespaceName: "db", ReleaseKey: "r2", Configurations: map[string]interface{}{"host": "h2"}})
+	s2 := c.GetSnapshot("db")
 
-	var restore = newNamespaceCahce()
-	defer restore.drain()
-	if err := restore.load(f.Name()); err != nil {
-		t.Error(err)
+	// s1 is not affected by later releases
+	if host, _ := s1.Get("host"); host != "h1" || s1.ReleaseKey() != "r1" || len(s1.Keys()) != 2 {
+		t.Errorf("unexpected snapshot r1:%v %v", s1.ReleaseKey(), s1.Values())
+	}
+	if _, ok := s2.Get("port"); ok || s2.ReleaseKey() != "r2" {
+		t.Errorf("unexpected snapshot r2:%v %v", s2.ReleaseKey(), s2.Values())
 	}
+	if key, ok := c.GetReleaseKey("db"); !ok || key != "r2" {
+		t.Errorf("release key expected:r2 got:%v %v", key, ok)
+	}
+	// nested values can't be modified through snapshot
+	c.handleResult(&result{NamespaceName: "db.yaml", Configurations: map[string]interface{}{"content": "hosts: [h1, h2]\n"}})
+	s3 := c.GetSnapshot("db.yaml")
+	hosts, _ := s3.Get("hosts")
+	hosts.([]interface{})[0] = "changed"
+	s3.Values()["hosts"].([]interface{})[1] = "changed"
+	if hosts, _ := s3.Get("hosts"); !reflect.DeepEqual(hosts, []interface{}{"h1", "h2"}) {
+		t.Errorf("snapshot should not be modified, got:%v", hosts)
+	}
+	if hosts, _ := c.GetStringSliceWithNamespace("db.yaml", "hosts"); !reflect.DeepEqual(hosts, []string{"h1", "h2"}) {
+		t.Errorf("cache should not be modified, got:%v", hosts)
+	}
+}
 
-	if val, _ := restore.mustGetCache("namespace").get("key"); val != "val" {
-		t.FailNow()
+func TestCacheConsistentRead(t *testing.T) {
+	cache := newCache()
+	cache.replace(map[string]interface{}{"host": 0, "port": 0}, "0")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 1000; i++ {
+			cache.replace(map[string]interface{}{"host": i, "port": i}, fmt.Sprint(i))
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		s := cache.snapshot("db")
+		host, _ := s.Get("host")
+		port, _ := s.Get("port")
+		if host != port || fmt.Sprint(host) != s.ReleaseKey() {
+			t.Fatalf("inconsistent snapshot:%v %v", s.ReleaseKey(), s.Values())
+		}
 	}
+	wg.Wait()
+}
 
-	if err := restore.load("null"); err == nil {
-		t.FailNow()
+func benchmarkValues(n int) map[string]interface{} {
+	values := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		values[fmt.Sprintf("key%d", i)] = fmt.Sprintf("val%d", i)
 	}
+	return values
+}
 
-	if err := restore.load("./testdata/app.properties"); err == nil {
-		t.FailNow()
+// BenchmarkCacheGet read from the immutable map while it's being replaced
+func BenchmarkCacheGet(b *testing.B) {
+	values := benchmarkValues(100)
+	cache := newCache()
+	cache.replace(values, "")
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				cache.replace(benchmarkValues(100), "")
+			}
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			cache.get(fmt.Sprintf("key%d", i%100))
+			i++
+		}
+	})
+}
+
+// BenchmarkSyncMapGet read from a sync.Map updated key by key, which is how
+// cache used to work
+func BenchmarkSyncMapGet(b *testing.B) {
+	var kv sync.Map
+	for k, v := range benchmarkValues(100) {
+		kv.Store(k, v)
 	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				for k, v := range benchmarkValues(100) {
+					kv.Store(k, v)
+				}
+			}
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			kv.Load(fmt.Sprintf("key%d", i%100))
+			i++
+		}
+	})
 }
@@ -0,0 +1,168 @@
+package agollo
+
+import (
+	"reflect"
+	"sync"
+)
+
+// chainRepo hold lookup chains, a chain is an ordered list of namespaces,
+// keys are resolved from the first namespace which contains it
+type chainRepo struct {
+	lock   sync.RWMutex
+	chains map[string][]string
+}
+
+func newChainRepo() *chainRepo {
+	return &chainRepo{chains: map[string][]string{}}
+}
+
+func (r *chainRepo) set(name string, namespaces []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(namespaces) == 0 {
+		delete(r.chains, name)
+		return
+	}
+	r.chains[name] = append([]string(nil), namespaces...)
+}
+
+func (r *chainRepo) get(name string) ([]string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	namespaces, ok := r.chains[name]
+	return namespaces, ok
+}
+
+// containing return names of chains which contain namespace
+func (r *chainRepo) containing(namespace string) []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var names []string
+	for name, namespaces := range r.chains {
+		if contains(namespaces, namespace) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SetChain define a lookup chain, getters called with name as namespace
+// resolve keys from the first namespace in namespaces which contains it, e.g.
+//
+//	client.SetChain("conf", "app.private", "shared.public", "application")
+//	client.GetStringWithNamespace("conf", "timeout")
+//
+// observers receive change events of the chain, with Namespace set to name,
+// only when the resolved value of a key changes. Namespaces should be
+// subscribed separately, calling SetChain without namespaces removes the chain
+func (c *Client) SetChain(name string, namespaces ...string) {
+	c.chains.set(name, namespaces)
+}
+
+// getChainValue resolve key through namespaces of a chain
+func (c *Client) getChainValue(namespaces []string, key string) (interface{}, bool) {
+	for _, namespace := range namespaces {
+		if val, ok := c.mustGetCache(namespace).get(key); ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// getRawValues return all values of namespace, or resolved values of a chain
+func (c *Client) getRawValues(namespace string) map[string]interface{} {
+	namespaces, ok := c.chains.get(namespace)
+	if !ok {
+		return c.mustGetCache(namespace).dump()
+	}
+
+	kv := map[string]interface{}{}
+	for i := len(namespaces) - 1; i >= 0; i-- {
+		for k, v := range c.mustGetCache(namespaces[i]).dump() {
+			kv[k] = v
+		}
+	}
+	return kv
+}
+
+// getValues is like getRawValues, placeholders are resolved if
+// conf.Interpolate is on
+func (c *Client) getValues(namespace string) map[string]interface{} {
+	kv := c.getRawValues(namespace)
+	if !c.conf.Interpolate {
+		return kv
+	}
+	for k := range kv {
+		if v, err := c.getValueE(namespace, k); err == nil {
+			kv[k] = v
+		} else {
+			defaultLogger.Printf("module:agollo method:getValues namespace:%s key:%s err:%v", namespace, k, err)
+		}
+	}
+	return kv
+}
+
+// chainChangeEvents generate change events of chains containing the namespace
+// of change, which is already applied to cache
+func (c *Client) chainChangeEvents(change *ChangeEvent) []*ChangeEvent {
+	var events []*ChangeEvent
+	for _, name := range c.chains.containing(change.Namespace) {
+		namespaces, ok := c.chains.get(name)
+		if !ok {
+			continue
+		}
+
+		event := c.derivedChangeEvent(change, name)
+		for key, ch := range change.Changes {
+			// resolve the value before change, with the old value of the
+			// changed namespace
+			var old interface{}
+			var oldOK bool
+			for _, namespace := range namespaces {
+				if namespace == change.Namespace {
+					old, oldOK = ch.OldValue, ch.ChangeType != ADD
+				} else {
+					old, oldOK = c.mustGetCache(namespace).get(key)
+				}
+				if oldOK {
+					break
+				}
+			}
+			val, ok := c.getChainValue(namespaces, key)
+
+			switch {
+			case !oldOK && ok:
+				event.Changes[key] = makeAddChange(key, val)
+			case oldOK && !ok:
+				event.Changes[key] = makeDeleteChange(key, old)
+			case oldOK && ok && !reflect.DeepEqual(old, val):
+				event.Changes[key] = makeModifyChange(key, old, val)
+			}
+		}
+
+		if len(event.Changes) > 0 {
+			event.Snapshot = newSnapshot(name, "", c.getRawValues(name))
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// derivedChangeEvent create an empty event of namespace, caused by change
+// of another namespace
+func (c *Client) derivedChangeEvent(change *ChangeEvent, namespace string) *ChangeEvent {
+	event := &ChangeEvent{
+		Namespace: namespace,
+		Changes:   map[string]*Change{},
+		Timestamp: change.Timestamp,
+		Source:    change.Source,
+	}
+	if _, ok := c.chains.get(namespace); !ok {
+		event.OldReleaseKey, _ = c.GetReleaseKey(namespace)
+		event.NewReleaseKey = event.OldReleaseKey
+	}
+	return event
+}
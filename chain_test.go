@@ -0,0 +1,60 @@
+package agollo
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	c := NewClient(&Conf{
+		AppID:    "ChainApp",
+		CacheDir: "/tmp/agollo",
+		Chains:   map[string][]string{"conf": {"app.private", "shared.public", defaultNamespace}},
+	})
+	c.handleResult(&result{NamespaceName: defaultNamespace, Configurations: map[string]interface{}{"timeout": "1", "name": "app"}})
+	c.handleResult(&result{NamespaceName: "shared.public", Configurations: map[string]interface{}{"timeout": "2", "region": "z1"}})
+
+	if v, ok := c.GetIntWithNamespace("conf", "timeout"); !ok || v != 2 {
+		t.Errorf("timeout expected:2 got:%v %v", v, ok)
+	}
+	if v, ok := c.GetStringWithNamespace("conf", "name"); !ok || v != "app" {
+		t.Errorf("name expected:app got:%v %v", v, ok)
+	}
+	if _, ok := c.GetStringWithNamespace("conf", "missing"); ok {
+		t.Errorf("missing key should not be found")
+	}
+
+	keys := c.GetAllKeys("conf")
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"name", "region", "timeout"}) {
+		t.Errorf("unexpected keys:%v", keys)
+	}
+
+	// shadowed by shared.public, no change of chain
+	change := c.handleResult(&result{NamespaceName: defaultNamespace, Configurations: map[string]interface{}{"timeout": "3", "name": "app"}})
+	if events := c.chainChangeEvents(change); len(events) != 0 {
+		t.Errorf("shadowed change should not fire, got:%v", events[0].Changes)
+	}
+
+	change = c.handleResult(&result{NamespaceName: "app.private", Configurations: map[string]interface{}{"timeout": "4", "name": "app"}})
+	events := c.chainChangeEvents(change)
+	if len(events) != 1 || events[0].Namespace != "conf" {
+		t.Fatalf("expected one chain event, got:%v", events)
+	}
+	expected := map[string]*Change{"timeout": makeModifyChange("timeout", "2", "4")}
+	if !reflect.DeepEqual(events[0].Changes, expected) {
+		t.Errorf("unexpected changes:%v", events[0].Changes)
+	}
+
+	change = c.handleResult(&result{NamespaceName: "shared.public", Configurations: map[string]interface{}{}})
+	events = c.chainChangeEvents(change)
+	if len(events) != 1 || !reflect.DeepEqual(events[0].Changes, map[string]*Change{"region": makeDeleteChange("region", "z1")}) {
+		t.Errorf("expected region deleted, got:%v", events)
+	}
+
+	c.SetChain("conf")
+	if _, ok := c.GetIntWithNamespace("conf", "timeout"); ok {
+		t.Errorf("removed chain should not resolve")
+	}
+}
@@ -1,5 +1,10 @@
 package agollo
 
+import (
+	"reflect"
+	"time"
+)
+
 // ChangeType for a key
 type ChangeType int
 
@@ -29,6 +34,18 @@ func (c ChangeType) String() string {
 type ChangeEvent struct {
 	Namespace string
 	Changes   map[string]*Change
+
+	// OldReleaseKey and NewReleaseKey are release keys before and after the
+	// change, they are the same for changes caused by other namespaces, e.g.
+	// placeholders, and empty for chains
+	OldReleaseKey string
+	NewReleaseKey string
+	// Timestamp is when the change is applied
+	Timestamp time.Time
+	// Source is where the new config comes from
+	Source Source
+	// Snapshot hold all values of namespace right after the change
+	Snapshot *Snapshot
 }
 
 // Change represent a single key change
@@ -59,3 +76,26 @@ func makeAddChange(_, value interface{}) *Change {
 		NewValue:   value,
 	}
 }
+
+// makeChanges compare values of a namespace before and after an update
+func makeChanges(oldValues, newValues map[string]interface{}) map[string]*Change {
+	changes := map[string]*Change{}
+	for k, v := range oldValues {
+		if _, ok := newValues[k]; !ok {
+			changes[k] = makeDeleteChange(k, v)
+		}
+	}
+
+	for k, v := range newValues {
+		old, ok := oldValues[k]
+		if !ok {
+			changes[k] = makeAddChange(k, v)
+			continue
+		}
+		// values of file namespaces may be maps or slices, which are not comparable
+		if !reflect.DeepEqual(old, v) {
+			changes[k] = makeModifyChange(k, old, v)
+		}
+	}
+	return changes
+}
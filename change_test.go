@@ -1,6 +1,10 @@
 package agollo
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestChangeType(t *testing.T) {
 	var tps = []ChangeType{ADD, MODIFY, DELETE, ChangeType(-1)}
@@ -32,3 +36,67 @@ func TestMakeAddChange(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestChangeEventMetadata(t *testing.T) {
+	c := NewClient(&Conf{AppID: "ChangeApp", CacheDir: "/tmp/agollo", Chains: map[string][]string{"conf": {defaultNamespace}}})
+	c.handleResult(&result{NamespaceName: defaultNamespace, ReleaseKey: "r1", Configurations: map[string]interface{}{"a": "1"}})
+
+	before := time.Now()
+	ce := c.handleResult(&result{NamespaceName: defaultNamespace, ReleaseKey: "r2", Configurations: map[string]interface{}{"a": "2", "b": "3"}})
+	if ce == nil {
+		t.Fatal("change event expected")
+	}
+	if ce.OldReleaseKey != "r1" || ce.NewReleaseKey != "r2" || ce.Source != SourceRemote || ce.Timestamp.Before(before) {
+		t.Errorf("unexpected metadata:%+v", ce)
+	}
+
+	snapshot := ce.Snapshot
+	if snapshot.Namespace() != defaultNamespace || snapshot.ReleaseKey() != "r2" {
+		t.Errorf("unexpected snapshot:%s %s", snapshot.Namespace(), snapshot.ReleaseKey())
+	}
+	if keys := snapshot.Keys(); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("unexpected keys:%v", keys)
+	}
+
+	// later updates and modification of Values don't affect the snapshot
+	snapshot.Values()["a"] = "x"
+	c.handleResult(&result{NamespaceName: defaultNamespace, ReleaseKey: "r3", Configurations: map[string]interface{}{"a": "4"}})
+	if v, ok := snapshot.Get("a"); !ok || v != "2" {
+		t.Errorf("snapshot should be immutable, got:%v", v)
+	}
+
+	events := c.chainChangeEvents(ce)
+	if len(events) != 1 || events[0].Snapshot == nil || events[0].Timestamp != ce.Timestamp || events[0].NewReleaseKey != "" {
+		t.Errorf("unexpected chain event:%+v", events)
+	}
+}
+
+func TestLocalChangeEvent(t *testing.T) {
+	store := NewMemoryCacheStore()
+	store.Save(&CacheEntry{Namespace: defaultNamespace, ReleaseKey: "r1", Configurations: map[string]interface{}{"a": "1"}})
+
+	c := NewClient(&Conf{AppID: "ChangeApp", CacheStore: store})
+	updates := c.WatchUpdate()
+	if err := c.loadLocal(defaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ce := <-updates:
+		if ce.Source != SourceCache || ce.NewReleaseKey != "r1" || !reflect.DeepEqual(ce.Changes, map[string]*Change{"a": makeAddChange("a", "1")}) {
+			t.Errorf("unexpected change event:%+v", ce)
+		}
+	default:
+		t.Fatal("change event expected after loading cache")
+	}
+
+	// nothing changed
+	if err := c.loadLocal(defaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ce := <-updates:
+		t.Errorf("unexpected change event:%+v", ce)
+	default:
+	}
+}
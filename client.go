@@ -3,44 +3,50 @@ package agollo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path"
-	"strconv"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/ZhengHe-MD/agollo/v4/parse"
 )
 
+// namespaceTyp is the suffix of file namespaces, see parse.Register
 type namespaceTyp string
 
-const (
-	emptyNamespaceTyp      namespaceTyp = ""
-	ymlNamespaceTyp        namespaceTyp = "yml"
-	yamlNamespaceTyp       namespaceTyp = "yaml"
-	jsonNamespaceTyp       namespaceTyp = "json"
-	propertiesNamespaceTyp namespaceTyp = "properties"
-)
-
 // Client for apollo
 type Client struct {
 	conf *Conf
 
-	updateChan chan *ChangeEvent
+	dispatcher dispatcher
 
-	caches         *namespaceCache
-	releaseKeyRepo *cache
+	caches   *namespaceCache
+	statuses *statusRepo
+	chains   *chainRepo
+	store    CacheStore
 
 	longPoller poller
 	requester  requester
+	servers    *serverRepo
+	// serversOnce start watching meta server once, however many times
+	// client is started
+	serversOnce sync.Once
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	// offline is true until GoOnline, see Conf.Offline
+	offlineLock       sync.Mutex
+	offline           bool
+	offlineNamespaces []string
+
 	observers []ChangeEventObserver
+	queues    []*observerQueue
 	mu        sync.RWMutex
+
+	wg sync.WaitGroup
 }
 
 // result of query config
@@ -55,63 +61,189 @@ type result struct {
 // NewClient create client from conf
 func NewClient(conf *Conf) *Client {
 	client := &Client{
-		conf:           conf,
-		caches:         newNamespaceCahce(),
-		releaseKeyRepo: newCache(),
+		conf:     conf,
+		caches:   newNamespaceCahce(),
+		statuses: newStatusRepo(),
+		chains:   newChainRepo(),
 
-		requester: newHTTPRequester(&http.Client{Timeout: queryTimeout}),
+		requester: newHTTPRequester(newHTTPClient(conf, queryTimeout), conf.AppID, conf.Secret),
 	}
 
-	client.longPoller = newLongPoller(conf, longPollInterval, client.handleNamespaceUpdate)
+	client.servers = newServerRepo(conf, newHTTPRequester(newHTTPClient(conf, queryTimeout), "", ""), serverCooldown)
+	client.longPoller = newLongPoller(conf, client.servers, longPollInterval, client.handleNamespaceUpdate)
 	client.ctx, client.cancel = context.WithCancel(context.Background())
+	client.store = conf.CacheStore
+	if client.store == nil {
+		client.store = newDefaultCacheStore(client.getDumpDir(), conf)
+	}
+	client.offline = conf.Offline
+	client.statuses.track(conf.NameSpaceNames...)
+	for name, namespaces := range conf.Chains {
+		client.chains.set(name, namespaces)
+	}
 	return client
 }
 
 // Start sync config
-func (c *Client) Start() (err error) {
+func (c *Client) Start() error {
+	return c.StartContext(context.Background())
+}
 
-	// check cache dir
-	if err = c.autoCreateCacheDir(); err != nil {
-		return err
+// StartContext sync config, ctx bounds requests made while preloading
+func (c *Client) StartContext(ctx context.Context) (err error) {
+
+	// cache dir is used by the default store only
+	if c.conf.CacheStore == nil {
+		// check cache dir
+		if err = c.autoCreateCacheDir(); err != nil {
+			return err
+		}
+
+		// migrate gob dump written by former versions
+		if namespaces, e := migrateLegacyDump(c.getDumpFileName(), c.store); e != nil {
+			defaultLogger.Printf("module:agollo method:Client.StartContext migrate dump err:%v", e)
+		} else if len(namespaces) > 0 {
+			defaultLogger.Printf("module:agollo method:Client.StartContext migrated namespaces:%v", namespaces)
+		}
 	}
 
+	if c.isOffline() {
+		// serve local files only until GoOnline
+		return c.loadOffline(c.conf.NameSpaceNames...)
+	}
+
+	c.watchServers(ctx)
+
 	// preload all config to local first
-	err = c.preload()
+	err = c.preload(ctx)
 
 	// start fetch update
-	go c.longPoller.start()
+	c.longPoller.start()
 
 	return
 }
 
+// watchServers discover config services from meta server, and keep them
+// refreshed by a single goroutine
+func (c *Client) watchServers(ctx context.Context) {
+	c.servers.refresh(ctx)
+	c.serversOnce.Do(func() {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.servers.watch(c.ctx, metaRefreshInterval)
+		}()
+	})
+}
+
 // handleNamespaceUpdate sync config for namespace, delivery changes to subscriber
-func (c *Client) handleNamespaceUpdate(namespace string) error {
-	change, err := c.sync(namespace)
+func (c *Client) handleNamespaceUpdate(ctx context.Context, namespace string) error {
+	change, err := c.sync(ctx, namespace)
 	if err != nil || change == nil {
 		return err
 	}
 
-	c.deliveryChangeEvent(change)
+	c.deliveryChangeEvents(change)
 	return nil
 }
 
-// Stop sync config
+// deliveryChangeEvents push change, and events of chains and keys depending
+// on it to subscriber
+func (c *Client) deliveryChangeEvents(change *ChangeEvent) {
+	// chain events compare raw values, so generate them before dependent
+	// keys are added to change
+	chainEvents := c.chainChangeEvents(change)
+	dependentEvents := c.dependentChangeEvents(change)
+
+	c.deliveryChangeEvent(change)
+	for _, event := range dependentEvents {
+		c.deliveryChangeEvent(event)
+	}
+	for _, event := range chainEvents {
+		c.deliveryChangeEvent(event)
+	}
+}
+
+// Stop sync config, it waits without deadline until every observer handled
+// delivered events, so an observer that never returns blocks it forever, use
+// StopContext for a deadline
 func (c *Client) Stop() error {
-	c.longPoller.stop()
+	return c.StopContext(context.Background())
+}
+
+// StopContext sync config, wait until background goroutines exit and
+// delivered change events are handled, or ctx is done
+func (c *Client) StopContext(ctx context.Context) error {
 	c.cancel()
-	// close(c.updateChan)
-	c.updateChan = nil
+	if err := c.longPoller.stop(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// senders give up once c.ctx is canceled, wait for observers to finish
+	// events already delivered
+	if err := c.dispatcher.stop(ctx); err != nil {
+		return err
+	}
+	for _, q := range c.getObserverQueues() {
+		q.close(true)
+	}
+	for _, q := range c.getObserverQueues() {
+		if err := q.wait(ctx); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// fetchAllCinfig fetch from remote, if failed load from local file
-func (c *Client) preload() error {
+// Ready block until all namespaces have a remote or cached value, or ctx is done
+func (c *Client) Ready(ctx context.Context) error {
+	return c.statuses.wait(ctx)
+}
+
+// WaitReady is like Ready, but with a timeout
+func (c *Client) WaitReady(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.Ready(ctx)
+}
+
+// GetNamespaceStatus return status of namespace
+func (c *Client) GetNamespaceStatus(namespace string) (NamespaceStatus, bool) {
+	return c.statuses.get(namespace)
+}
+
+// GetNamespaceStatuses return status of all namespaces, sorted by namespace
+func (c *Client) GetNamespaceStatuses() []NamespaceStatus {
+	return c.statuses.all()
+}
+
+// GetPollerState return state of the long poller, for diagnostics
+func (c *Client) GetPollerState() PollerState {
+	return c.longPoller.state()
+}
+
+// fetchAllCinfig fetch from remote, namespaces failed are loaded from local file
+func (c *Client) preload(ctx context.Context) error {
 	var err error
 	for _, v := range c.conf.NameSpaceNames {
-		if _, e := c.sync(v); e != nil {
-			defaultLogger.Printf("module:agollo method:preload namespace:%v, err:%v", v, err)
-			if e1 := c.loadLocal(c.getDumpFileName()); e1 != nil {
-				err = e1
+		if _, e := c.sync(ctx, v); e != nil {
+			defaultLogger.Printf("module:agollo method:preload namespace:%v, err:%v", v, e)
+			if e1 := c.loadLocal(v); e1 != nil {
+				defaultLogger.Printf("module:agollo method:preload namespace:%v, loadLocal err:%v", v, e1)
+				err = &fallbackError{err: e, local: e1}
 			}
 		}
 	}
@@ -119,22 +251,85 @@ func (c *Client) preload() error {
 	return err
 }
 
-// loadLocal load caches from local file
-func (c *Client) loadLocal(name string) error {
-	return c.caches.load(name)
+// loadLocal load cache of namespace from store, namespaces fetched from
+// remote are kept, since store is never newer
+func (c *Client) loadLocal(namespace string) error {
+	entry, err := c.store.Load(namespace)
+	if err != nil {
+		return err
+	}
+
+	if status, ok := c.statuses.get(namespace); ok && status.Source == SourceRemote {
+		return nil
+	}
+	c.resetLocal(entry, SourceCache)
+	return nil
 }
 
-// dump caches to file
-func (c *Client) dump(name string) error {
-	return c.caches.dump(name)
+// resetLocal replace values of namespace with entry loaded locally, and
+// delivery changes with source
+func (c *Client) resetLocal(entry *CacheEntry, source Source) {
+	status, _ := c.statuses.get(entry.Namespace)
+	cache := c.mustGetCache(entry.Namespace)
+	values := c.getEntryValues(entry)
+
+	change := &ChangeEvent{
+		Namespace:     entry.Namespace,
+		Changes:       makeChanges(cache.values(), values),
+		OldReleaseKey: status.ReleaseKey,
+		NewReleaseKey: entry.ReleaseKey,
+		Timestamp:     time.Now(),
+		Source:        source,
+	}
+	cache.reset(values)
+	c.statuses.set(entry.Namespace, source, entry.ReleaseKey)
+
+	if len(change.Changes) > 0 {
+		change.Snapshot = newSnapshot(entry.Namespace, entry.ReleaseKey, values)
+		c.deliveryChangeEvents(change)
+	}
 }
 
-// WatchUpdate get all updates
-func (c *Client) WatchUpdate() <-chan *ChangeEvent {
-	if c.updateChan == nil {
-		c.updateChan = make(chan *ChangeEvent, 32)
+// getEntryValues return parsed values of entry
+func (c *Client) getEntryValues(entry *CacheEntry) map[string]interface{} {
+	if entry.Values != nil {
+		return entry.Values
 	}
-	return c.updateChan
+	parser := parse.GetParser(string(c.getNameSpaceTyp(entry.Namespace)))
+	return c.getConfigurations(entry.Namespace, parser, entry.Configurations)
+}
+
+// dump save result to store
+func (c *Client) dump(result *result, fetchTime time.Time) error {
+	return c.store.Save(&CacheEntry{
+		Namespace:      result.NamespaceName,
+		ReleaseKey:     result.ReleaseKey,
+		FetchTime:      fetchTime,
+		Configurations: result.Configurations,
+	})
+}
+
+// WatchUpdate get all updates, the channel is closed when client stops.
+// Events are consumed by StartWatchUpdate too, use one of them only
+func (c *Client) WatchUpdate() <-chan *ChangeEvent {
+	return c.dispatcher.watch()
+}
+
+// StartWatchUpdate deliver change events to all observers in a background
+// goroutine until client stops, calling it more than once is a no-op.
+// Each observer has its own queue and goroutine, see Conf.ObserverOverflow
+func (c *Client) StartWatchUpdate() {
+	c.dispatcher.start(func(event *ChangeEvent) {
+		for _, q := range c.getObserverQueues() {
+			q.push(event)
+		}
+	})
+}
+
+// WaitDispatch block until change events delivered after StartWatchUpdate
+// are handled or dropped by observers, or ctx is done
+func (c *Client) WaitDispatch(ctx context.Context) error {
+	return c.dispatcher.wait(ctx)
 }
 
 func (c *Client) mustGetCache(namespace string) *cache {
@@ -143,50 +338,264 @@ func (c *Client) mustGetCache(namespace string) *cache {
 
 // SubscribeToNamespaces fetch namespace config to local and subscribe to updates
 func (c *Client) SubscribeToNamespaces(namespaces ...string) error {
-	return c.longPoller.addNamespaces(namespaces...)
+	return c.SubscribeToNamespacesContext(context.Background(), namespaces...)
 }
 
-func (c *Client) GetStringWithNamespace(namespace, key string) (string, bool) {
-	cache := c.mustGetCache(namespace)
-	val, ok := cache.get(key)
-	if !ok {
-		return "", false
+// SubscribeToNamespacesContext is like SubscribeToNamespaces, ctx bounds the requests
+func (c *Client) SubscribeToNamespacesContext(ctx context.Context, namespaces ...string) error {
+	c.statuses.track(namespaces...)
+	if c.subscribeOffline(namespaces...) {
+		return c.loadOffline(namespaces...)
+	}
+	return c.longPoller.addNamespaces(ctx, namespaces...)
+}
+
+// getRawValue return raw value of key in namespace, or resolved value if
+// namespace is a chain
+func (c *Client) getRawValue(namespace, key string) (interface{}, bool) {
+	if namespaces, ok := c.chains.get(namespace); ok {
+		return c.getChainValue(namespaces, key)
 	}
-	strVal, ok := val.(string)
+	return c.mustGetCache(namespace).get(key)
+}
+
+// getValue return value of key in namespace, placeholders are resolved if
+// conf.Interpolate is on
+func (c *Client) getValue(namespace, key string) (interface{}, bool) {
+	val, err := c.getValueE(namespace, key)
+	return val, err == nil
+}
+
+// getValueE is like getValue, but report missing key and circular reference
+// as GetError
+func (c *Client) getValueE(namespace, key string) (interface{}, error) {
+	val, ok := c.getRawValue(namespace, key)
 	if !ok {
+		return nil, &GetError{Namespace: namespace, Key: key, Err: ErrKeyNotFound}
+	}
+	if !c.conf.Interpolate {
+		return val, nil
+	}
+	ret, _, err := newInterpolator(c.rawLookup(nil)).resolve(namespace, key)
+	if err != nil {
+		return nil, newGetError(namespace, key, val, err)
+	}
+	return ret, nil
+}
+
+func (c *Client) GetStringWithNamespaceE(namespace, key string) (string, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return "", err
+	}
+	v, err := toString(val)
+	if err != nil {
+		return "", newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetStringE(key string) (string, error) {
+	return c.GetStringWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetStringWithNamespace(namespace, key string) (string, bool) {
+	v, err := c.GetStringWithNamespaceE(namespace, key)
+	if err != nil {
 		return "", false
 	}
-	return strVal, true
+	return v, true
 }
 
 func (c *Client) GetString(key string) (string, bool) {
 	return c.GetStringWithNamespace(defaultNamespace, key)
 }
 
-func (c *Client) GetIntWithNamespace(namespace, key string) (int, bool) {
-	cache := c.mustGetCache(namespace)
-	val, ok := cache.get(key)
-	if !ok {
-		return 0, false
+func (c *Client) GetStringWithNamespaceOr(namespace, key string, def string) string {
+	if v, err := c.GetStringWithNamespaceE(namespace, key); err == nil {
+		return v
 	}
-	IntVal, ok := val.(int)
-	if !ok {
+	return def
+}
+
+func (c *Client) GetStringOr(key string, def string) string {
+	return c.GetStringWithNamespaceOr(defaultNamespace, key, def)
+}
+
+func (c *Client) GetIntWithNamespaceE(namespace, key string) (int, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := toInt(val)
+	if err != nil {
+		return 0, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetIntE(key string) (int, error) {
+	return c.GetIntWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetIntWithNamespace(namespace, key string) (int, bool) {
+	v, err := c.GetIntWithNamespaceE(namespace, key)
+	if err != nil {
 		return 0, false
 	}
-	return IntVal, true
+	return v, true
 }
 
 func (c *Client) GetInt(key string) (int, bool) {
 	return c.GetIntWithNamespace(defaultNamespace, key)
 }
 
-func (c *Client) GetFloat64WithNamespace(namespace, key string) (float64, bool) {
-	s, ok := c.GetStringWithNamespace(namespace, key)
-	if !ok {
+func (c *Client) GetIntWithNamespaceOr(namespace, key string, def int) int {
+	if v, err := c.GetIntWithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetIntOr(key string, def int) int {
+	return c.GetIntWithNamespaceOr(defaultNamespace, key, def)
+}
+
+func (c *Client) GetInt64WithNamespaceE(namespace, key string) (int64, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := toInt64(val)
+	if err != nil {
+		return 0, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetInt64E(key string) (int64, error) {
+	return c.GetInt64WithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetInt64WithNamespace(namespace, key string) (int64, bool) {
+	v, err := c.GetInt64WithNamespaceE(namespace, key)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func (c *Client) GetInt64(key string) (int64, bool) {
+	return c.GetInt64WithNamespace(defaultNamespace, key)
+}
+
+func (c *Client) GetInt64WithNamespaceOr(namespace, key string, def int64) int64 {
+	if v, err := c.GetInt64WithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetInt64Or(key string, def int64) int64 {
+	return c.GetInt64WithNamespaceOr(defaultNamespace, key, def)
+}
+
+func (c *Client) GetUintWithNamespaceE(namespace, key string) (uint, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := toUint(val)
+	if err != nil {
+		return 0, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetUintE(key string) (uint, error) {
+	return c.GetUintWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetUintWithNamespace(namespace, key string) (uint, bool) {
+	v, err := c.GetUintWithNamespaceE(namespace, key)
+	if err != nil {
 		return 0, false
 	}
+	return v, true
+}
+
+func (c *Client) GetUint(key string) (uint, bool) {
+	return c.GetUintWithNamespace(defaultNamespace, key)
+}
+
+func (c *Client) GetUintWithNamespaceOr(namespace, key string, def uint) uint {
+	if v, err := c.GetUintWithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetUintOr(key string, def uint) uint {
+	return c.GetUintWithNamespaceOr(defaultNamespace, key, def)
+}
+
+func (c *Client) GetFloat32WithNamespaceE(namespace, key string) (float32, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := toFloat32(val)
+	if err != nil {
+		return 0, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetFloat32E(key string) (float32, error) {
+	return c.GetFloat32WithNamespaceE(defaultNamespace, key)
+}
 
-	v, err := strconv.ParseFloat(s, 64)
+func (c *Client) GetFloat32WithNamespace(namespace, key string) (float32, bool) {
+	v, err := c.GetFloat32WithNamespaceE(namespace, key)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func (c *Client) GetFloat32(key string) (float32, bool) {
+	return c.GetFloat32WithNamespace(defaultNamespace, key)
+}
+
+func (c *Client) GetFloat32WithNamespaceOr(namespace, key string, def float32) float32 {
+	if v, err := c.GetFloat32WithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetFloat32Or(key string, def float32) float32 {
+	return c.GetFloat32WithNamespaceOr(defaultNamespace, key, def)
+}
+
+func (c *Client) GetFloat64WithNamespaceE(namespace, key string) (float64, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := toFloat64(val)
+	if err != nil {
+		return 0, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetFloat64E(key string) (float64, error) {
+	return c.GetFloat64WithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetFloat64WithNamespace(namespace, key string) (float64, bool) {
+	v, err := c.GetFloat64WithNamespaceE(namespace, key)
 	if err != nil {
 		return 0, false
 	}
@@ -197,87 +606,311 @@ func (c *Client) GetFloat64(key string) (float64, bool) {
 	return c.GetFloat64WithNamespace(defaultNamespace, key)
 }
 
-func (c *Client) GetBoolWithNamespace(namespace, key string) (bool, bool) {
-	s, ok := c.GetStringWithNamespace(namespace, key)
-	if !ok {
-		return false, false
+func (c *Client) GetFloat64WithNamespaceOr(namespace, key string, def float64) float64 {
+	if v, err := c.GetFloat64WithNamespaceE(namespace, key); err == nil {
+		return v
 	}
+	return def
+}
+
+func (c *Client) GetFloat64Or(key string, def float64) float64 {
+	return c.GetFloat64WithNamespaceOr(defaultNamespace, key, def)
+}
 
-	b, err := strconv.ParseBool(s)
+func (c *Client) GetBoolWithNamespaceE(namespace, key string) (bool, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return false, err
+	}
+	v, err := toBool(val)
+	if err != nil {
+		return false, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetBoolE(key string) (bool, error) {
+	return c.GetBoolWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetBoolWithNamespace(namespace, key string) (bool, bool) {
+	v, err := c.GetBoolWithNamespaceE(namespace, key)
 	if err != nil {
 		return false, false
 	}
-	return b, true
+	return v, true
 }
 
 func (c *Client) GetBool(key string) (bool, bool) {
 	return c.GetBoolWithNamespace(defaultNamespace, key)
 }
 
-func (c *Client) GetIntSliceWithNamespace(namespace, key string) ([]int, bool) {
-	cache := c.mustGetCache(namespace)
-	val, ok := cache.get(key)
-	if !ok {
-		return []int{}, false
+func (c *Client) GetBoolWithNamespaceOr(namespace, key string, def bool) bool {
+	if v, err := c.GetBoolWithNamespaceE(namespace, key); err == nil {
+		return v
 	}
-	intSliceIfVal, ok := val.([]interface{})
-	if !ok {
-		return []int{}, false
+	return def
+}
+
+func (c *Client) GetBoolOr(key string, def bool) bool {
+	return c.GetBoolWithNamespaceOr(defaultNamespace, key, def)
+}
+
+// GetDurationWithNamespaceE accept values like "1m30s", or integers as nanoseconds
+func (c *Client) GetDurationWithNamespaceE(namespace, key string) (time.Duration, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return 0, err
 	}
-	intSlices := make([]int, len(intSliceIfVal))
-	for idx, intIfVal := range intSliceIfVal {
-		intData, ok := intIfVal.(int)
-		if !ok {
-			defaultLogger.Printf("module:agollo method:GetStringSliceWithNamespace assertion failed")
-			return []int{}, false
-		}
-		intSlices[idx] = intData
+	v, err := toDuration(val)
+	if err != nil {
+		return 0, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetDurationE(key string) (time.Duration, error) {
+	return c.GetDurationWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetDurationWithNamespace(namespace, key string) (time.Duration, bool) {
+	v, err := c.GetDurationWithNamespaceE(namespace, key)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func (c *Client) GetDuration(key string) (time.Duration, bool) {
+	return c.GetDurationWithNamespace(defaultNamespace, key)
+}
+
+func (c *Client) GetDurationWithNamespaceOr(namespace, key string, def time.Duration) time.Duration {
+	if v, err := c.GetDurationWithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetDurationOr(key string, def time.Duration) time.Duration {
+	return c.GetDurationWithNamespaceOr(defaultNamespace, key, def)
+}
+
+// GetTimeWithNamespaceE parse value by TimeLayouts, or integers as unix seconds
+func (c *Client) GetTimeWithNamespaceE(namespace, key string) (time.Time, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	v, err := toTime(val)
+	if err != nil {
+		return time.Time{}, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetTimeE(key string) (time.Time, error) {
+	return c.GetTimeWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetTimeWithNamespace(namespace, key string) (time.Time, bool) {
+	v, err := c.GetTimeWithNamespaceE(namespace, key)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return v, true
+}
+
+func (c *Client) GetTime(key string) (time.Time, bool) {
+	return c.GetTimeWithNamespace(defaultNamespace, key)
+}
+
+func (c *Client) GetTimeWithNamespaceOr(namespace, key string, def time.Time) time.Time {
+	if v, err := c.GetTimeWithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetTimeOr(key string, def time.Time) time.Time {
+	return c.GetTimeWithNamespaceOr(defaultNamespace, key, def)
+}
+
+// GetSizeInBytesWithNamespaceE accept values like "512", "64k", "10MB" or "1GiB",
+// units are based on 1024
+func (c *Client) GetSizeInBytesWithNamespaceE(namespace, key string) (uint64, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := toSizeInBytes(val)
+	if err != nil {
+		return 0, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetSizeInBytesE(key string) (uint64, error) {
+	return c.GetSizeInBytesWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetSizeInBytesWithNamespace(namespace, key string) (uint64, bool) {
+	v, err := c.GetSizeInBytesWithNamespaceE(namespace, key)
+	if err != nil {
+		return 0, false
 	}
-	return intSlices, true
+	return v, true
+}
+
+func (c *Client) GetSizeInBytes(key string) (uint64, bool) {
+	return c.GetSizeInBytesWithNamespace(defaultNamespace, key)
+}
+
+func (c *Client) GetSizeInBytesWithNamespaceOr(namespace, key string, def uint64) uint64 {
+	if v, err := c.GetSizeInBytesWithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetSizeInBytesOr(key string, def uint64) uint64 {
+	return c.GetSizeInBytesWithNamespaceOr(defaultNamespace, key, def)
+}
+
+// GetIntSliceWithNamespaceE accept lists of file namespaces or comma separated values
+func (c *Client) GetIntSliceWithNamespaceE(namespace, key string) ([]int, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	v, err := toIntSlice(val)
+	if err != nil {
+		return nil, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetIntSliceE(key string) ([]int, error) {
+	return c.GetIntSliceWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetIntSliceWithNamespace(namespace, key string) ([]int, bool) {
+	v, err := c.GetIntSliceWithNamespaceE(namespace, key)
+	if err != nil {
+		return []int{}, false
+	}
+	return v, true
 }
 
 func (c *Client) GetIntSlice(key string) ([]int, bool) {
 	return c.GetIntSliceWithNamespace(defaultNamespace, key)
 }
 
-func (c *Client) GetStringSliceWithNamespace(namespace, key string) ([]string, bool) {
-	cache := c.mustGetCache(namespace)
-	val, ok := cache.get(key)
-	if !ok {
-		return []string{}, false
+func (c *Client) GetIntSliceWithNamespaceOr(namespace, key string, def []int) []int {
+	if v, err := c.GetIntSliceWithNamespaceE(namespace, key); err == nil {
+		return v
 	}
-	stringSliceIfVal, ok := val.([]interface{})
-	if !ok {
-		return []string{}, false
+	return def
+}
+
+func (c *Client) GetIntSliceOr(key string, def []int) []int {
+	return c.GetIntSliceWithNamespaceOr(defaultNamespace, key, def)
+}
+
+// GetStringSliceWithNamespaceE accept lists of file namespaces or comma separated values
+func (c *Client) GetStringSliceWithNamespaceE(namespace, key string) ([]string, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return nil, err
 	}
-	stringSlices := make([]string, len(stringSliceIfVal))
-	for idx, stringIfVal := range stringSliceIfVal {
-		stringData, ok := stringIfVal.(string)
-		if !ok {
-			defaultLogger.Printf("module:agollo method:GetStringSliceWithNamespace assertion failed")
-			return []string{}, false
-		}
-		stringSlices[idx] = stringData
+	v, err := toStringSlice(val)
+	if err != nil {
+		return nil, newGetError(namespace, key, val, err)
 	}
-	return stringSlices, true
+	return v, nil
+}
+
+func (c *Client) GetStringSliceE(key string) ([]string, error) {
+	return c.GetStringSliceWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetStringSliceWithNamespace(namespace, key string) ([]string, bool) {
+	v, err := c.GetStringSliceWithNamespaceE(namespace, key)
+	if err != nil {
+		return []string{}, false
+	}
+	return v, true
 }
 
 func (c *Client) GetStringSlice(key string) ([]string, bool) {
 	return c.GetStringSliceWithNamespace(defaultNamespace, key)
 }
 
+func (c *Client) GetStringSliceWithNamespaceOr(namespace, key string, def []string) []string {
+	if v, err := c.GetStringSliceWithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetStringSliceOr(key string, def []string) []string {
+	return c.GetStringSliceWithNamespaceOr(defaultNamespace, key, def)
+}
+
+// GetStringMapStringWithNamespaceE accept sub tree of file namespaces or values like "k1=v1,k2=v2"
+func (c *Client) GetStringMapStringWithNamespaceE(namespace, key string) (map[string]string, error) {
+	val, err := c.getValueE(namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	v, err := toStringMapString(val)
+	if err != nil {
+		return nil, newGetError(namespace, key, val, err)
+	}
+	return v, nil
+}
+
+func (c *Client) GetStringMapStringE(key string) (map[string]string, error) {
+	return c.GetStringMapStringWithNamespaceE(defaultNamespace, key)
+}
+
+func (c *Client) GetStringMapStringWithNamespace(namespace, key string) (map[string]string, bool) {
+	v, err := c.GetStringMapStringWithNamespaceE(namespace, key)
+	if err != nil {
+		return map[string]string{}, false
+	}
+	return v, true
+}
+
+func (c *Client) GetStringMapString(key string) (map[string]string, bool) {
+	return c.GetStringMapStringWithNamespace(defaultNamespace, key)
+}
+
+func (c *Client) GetStringMapStringWithNamespaceOr(namespace, key string, def map[string]string) map[string]string {
+	if v, err := c.GetStringMapStringWithNamespaceE(namespace, key); err == nil {
+		return v
+	}
+	return def
+}
+
+func (c *Client) GetStringMapStringOr(key string, def map[string]string) map[string]string {
+	return c.GetStringMapStringWithNamespaceOr(defaultNamespace, key, def)
+}
+
 func (c *Client) GetNamespaceContent(namespace string) (string, bool) {
 	namespaceTyp := c.getNameSpaceTyp(namespace)
 	return c.GetStringWithNamespace(namespace, string(namespaceTyp)+"content")
 }
 
 // 只有文件类型配置可以 Unmarshal, 类似： properties 这种配置类型是 key , value 结构,没有所谓 content 字段，不适合 Unmarshal
+// key , value 结构的配置可以使用 Bind。没有 content 时返回包装 ErrKeyNotFound 的 *GetError
 func (c *Client) GetNamespaceVal(namespace string, val interface{}) error {
 	namespaceTyp := c.getNameSpaceTyp(namespace)
 	parser := parse.GetParser(string(namespaceTyp))
-	content, ok := c.GetStringWithNamespace(namespace, string(namespaceTyp)+"content")
+	key := string(namespaceTyp) + "content"
+	content, ok := c.GetStringWithNamespace(namespace, key)
 	if !ok {
-		return nil
+		return &GetError{Namespace: namespace, Key: key, Err: ErrKeyNotFound}
 	}
 	if err := parser.Unmarshal([]byte(content), val); err != nil {
 		return err
@@ -287,30 +920,40 @@ func (c *Client) GetNamespaceVal(namespace string, val interface{}) error {
 
 // GetAllKeys return all config keys in given namespace
 func (c *Client) GetAllKeys(namespace string) []string {
-	var keys []string
-	cache := c.mustGetCache(namespace)
-	cache.kv.Range(func(key, value interface{}) bool {
-		str, ok := key.(string)
-		if ok {
-			keys = append(keys, str)
+	if _, ok := c.chains.get(namespace); ok {
+		var keys []string
+		for key := range c.getValues(namespace) {
+			keys = append(keys, key)
 		}
-		return true
-	})
+		return keys
+	}
+
+	var keys []string
+	for key := range c.mustGetCache(namespace).values() {
+		keys = append(keys, key)
+	}
 	return keys
 }
 
 // sync namespace config
-func (c *Client) sync(namesapce string) (*ChangeEvent, error) {
+func (c *Client) sync(ctx context.Context, namesapce string) (*ChangeEvent, error) {
 	releaseKey, _ := c.GetReleaseKey(namesapce)
-	url := configURL(c.conf, namesapce, releaseKey)
-	bts, err := c.requester.request(url)
-	defaultLogger.Printf("module:agollo method:Client.sync url:%s data:%s err:%v", url, bts, err)
-	if err != nil || len(bts) == 0 {
-		return nil, err
+	bts, err := c.servers.do(ctx, func(server string) ([]byte, error) {
+		url := configURL(server, c.conf, namesapce, releaseKey)
+		bts, err := c.requester.request(ctx, url)
+		defaultLogger.Printf("module:agollo method:Client.sync url:%s data:%s err:%v", url, bts, err)
+		return bts, err
+	})
+	if errors.Is(err, ErrNotModified) {
+		c.statuses.set(namesapce, SourceRemote, releaseKey)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &NamespaceError{Namespace: namesapce, Err: err}
 	}
 	var result result
 	if err := json.Unmarshal(bts, &result); err != nil {
-		return nil, err
+		return nil, &NamespaceError{Namespace: namesapce, Err: err}
 	}
 
 	return c.handleResult(&result), nil
@@ -318,58 +961,50 @@ func (c *Client) sync(namesapce string) (*ChangeEvent, error) {
 
 // deliveryChangeEvent push change to subscriber
 func (c *Client) deliveryChangeEvent(change *ChangeEvent) {
-	if c.updateChan == nil {
-		return
-	}
-	select {
-	case <-c.ctx.Done():
-	case c.updateChan <- change:
-	}
+	c.dispatcher.send(c.ctx, change)
 }
 
 // handleResult generate changes from query result, and update local cache
 func (c *Client) handleResult(result *result) *ChangeEvent {
+	oldReleaseKey, _ := c.GetReleaseKey(result.NamespaceName)
 	var ret = ChangeEvent{
-		Namespace: result.NamespaceName,
-		Changes:   map[string]*Change{},
+		Namespace:     result.NamespaceName,
+		OldReleaseKey: oldReleaseKey,
+		NewReleaseKey: result.ReleaseKey,
+		Timestamp:     time.Now(),
+		Source:        SourceRemote,
 	}
 	parser := parse.GetParser(string(c.getNameSpaceTyp(result.NamespaceName)))
 	cache := c.mustGetCache(result.NamespaceName)
-	kv := cache.dump()
+	kv := cache.values()
 
-	newConfigurations := c.getConfigurations(parser, result.Configurations)
+	newConfigurations := c.getConfigurations(result.NamespaceName, parser, result.Configurations)
+	ret.Changes = makeChanges(kv, newConfigurations)
 
-	for k, v := range kv {
-		if _, ok := newConfigurations[k]; !ok {
-			cache.delete(k)
-			ret.Changes[k] = makeDeleteChange(k, v)
-		}
-	}
+	// swap in values and release key at once, readers see either the old
+	// release or the new one
+	cache.replace(newConfigurations, result.ReleaseKey)
+	c.statuses.set(result.NamespaceName, SourceRemote, result.ReleaseKey)
 
-	for k, v := range newConfigurations {
-		cache.set(k, v)
-		old, ok := kv[k]
-		if !ok {
-			ret.Changes[k] = makeAddChange(k, v)
-			continue
-		}
-		if old != v {
-			ret.Changes[k] = makeModifyChange(k, old, v)
-		}
+	// dump namespace to file
+	if err := c.dump(result, ret.Timestamp); err != nil {
+		defaultLogger.Printf("module:agollo method:Client.handleResult namespace:%s dump err:%v", result.NamespaceName, err)
 	}
 
-	c.setReleaseKey(result.NamespaceName, result.ReleaseKey)
-
-	// dump caches to file
-	c.dump(c.getDumpFileName())
-
 	if len(ret.Changes) == 0 {
 		return nil
 	}
 
+	ret.Snapshot = newSnapshot(result.NamespaceName, result.ReleaseKey, newConfigurations)
 	return &ret
 }
 
+// getDumpDir return dir of local cache files, one file for each namespace
+func (c *Client) getDumpDir() string {
+	return path.Join(c.conf.CacheDir, fmt.Sprintf("%s_%s", c.conf.AppID, c.conf.Cluster))
+}
+
+// getDumpFileName return name of the gob dump written by former versions
 func (c *Client) getDumpFileName() string {
 	cacheDir := c.conf.CacheDir
 	fileName := fmt.Sprintf(".%s_%s", c.conf.AppID, c.conf.Cluster)
@@ -378,19 +1013,20 @@ func (c *Client) getDumpFileName() string {
 
 // GetReleaseKey return release key for namespace
 func (c *Client) GetReleaseKey(namespace string) (string, bool) {
-	val, ok := c.releaseKeyRepo.get(namespace)
+	cache, ok := c.caches.getCache(namespace)
 	if !ok {
 		return "", false
 	}
-	strVal, ok := val.(string)
-	if !ok {
-		return "", false
-	}
-	return strVal, true
+	return cache.getReleaseKey()
 }
 
-func (c *Client) setReleaseKey(namespace, releaseKey string) {
-	c.releaseKeyRepo.set(namespace, releaseKey)
+// GetSnapshot return values of namespace at its current release, use it to
+// read many keys consistently, values of chains are merged
+func (c *Client) GetSnapshot(namespace string) *Snapshot {
+	if _, ok := c.chains.get(namespace); ok {
+		return newSnapshot(namespace, "", c.getRawValues(namespace))
+	}
+	return c.mustGetCache(namespace).snapshot(namespace)
 }
 
 // autoCreateCacheDir autoCreateCacheDir
@@ -416,20 +1052,42 @@ func (c *Client) registerObserver(observer ChangeEventObserver) {
 	defer c.mu.Unlock()
 
 	c.observers = append(c.observers, observer)
+	c.queues = append(c.queues, newObserverQueue(observer, c.conf.ObserverQueueSize, c.conf.ObserverOverflow, c.dispatcher.addPending))
 }
 
 func (c *Client) recallObserver(ob ChangeEventObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	var newObservers []ChangeEventObserver
-	for _, observer := range c.getObservers() {
+	var newQueues []*observerQueue
+	for i, observer := range c.observers {
 		if observer != ob {
 			newObservers = append(newObservers, observer)
+			newQueues = append(newQueues, c.queues[i])
+			continue
 		}
+		c.queues[i].close(false)
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	c.observers = newObservers
+	c.queues = newQueues
+}
+
+func (c *Client) getObserverQueues() []*observerQueue {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.queues
+}
+
+// GetObserverStats return queue depth and delivery counters of all observers
+func (c *Client) GetObserverStats() []ObserverStats {
+	var stats []ObserverStats
+	for _, q := range c.getObserverQueues() {
+		stats = append(stats, q.getStats())
+	}
+	return stats
 }
 
 func (c *Client) getObservers() []ChangeEventObserver {
@@ -440,26 +1098,21 @@ func (c *Client) getObservers() []ChangeEventObserver {
 }
 
 func (c *Client) getNameSpaceTyp(namespace string) namespaceTyp {
-	if strings.HasSuffix(namespace, ".yml") {
-		return ymlNamespaceTyp
-	}
-	if strings.HasSuffix(namespace, ".yaml") {
-		return yamlNamespaceTyp
-	}
-	if strings.HasSuffix(namespace, ".json") {
-		return jsonNamespaceTyp
-	}
-	if strings.HasSuffix(namespace, ".properties") {
-		return propertiesNamespaceTyp
-	}
-	return emptyNamespaceTyp
+	return namespaceTyp(parse.GetNamespaceType(namespace))
 }
 
-func (c *Client) getConfigurations(parser parse.ContentParser, configurations map[string]interface{}) map[string]interface{} {
+func (c *Client) getConfigurations(namespace string, parser parse.ContentParser, configurations map[string]interface{}) map[string]interface{} {
 	newConfigurations := make(map[string]interface{})
 	for key, val := range configurations {
+		// only content of file namespaces is parsed, other keys are plain
+		// key/value pairs, e.g. a .properties namespace
+		if key != "content" {
+			newConfigurations[key] = val
+			continue
+		}
 		tempConfigurations, err := parser.Parse(val)
 		if err != nil {
+			defaultLogger.Printf("module:agollo method:Client.getConfigurations namespace:%v, parse content err:%v", namespace, err)
 			continue
 		}
 		if tempConfigurations == nil {
@@ -1,6 +1,20 @@
 package agollo
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZhengHe-MD/agollo/v4/parse"
+)
 
 type simpleMockObserver struct {}
 
@@ -41,3 +55,234 @@ func TestObserver(t *testing.T) {
 		}
 	})
 }
+
+func TestStartStopContext(t *testing.T) {
+	serv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// hang until client gives up
+		<-req.Context().Done()
+	}))
+	defer serv.Close()
+
+	c := NewClient(&Conf{
+		AppID:          "HangApp",
+		Cluster:        "default",
+		NameSpaceNames: []string{"hang"},
+		CacheDir:       "/tmp/agollo",
+		IP:             serv.URL,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	start := time.Now()
+	if err := c.StartContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StartContext should fail with deadline exceeded, got:%v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= queryTimeout {
+		t.Errorf("StartContext should be bounded by ctx, took:%v", elapsed)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if err := c.SubscribeToNamespacesContext(ctx, "another"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SubscribeToNamespacesContext should fail with deadline exceeded, got:%v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.StopContext(ctx); err != nil {
+		t.Fatalf("StopContext should succeed, got:%v", err)
+	}
+
+	select {
+	case <-c.longPoller.(*longPoller).done:
+	default:
+		t.Errorf("poller goroutine should exit after StopContext")
+	}
+}
+
+func TestPreloadFallback(t *testing.T) {
+	serv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/good") {
+			rw.Write([]byte(`{"namespaceName":"good","configurations":{"key":"fresh"},"releaseKey":"2"}`))
+			return
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer serv.Close()
+
+	dir, err := ioutil.TempDir("", "agollo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewClient(&Conf{
+		AppID:          "FallbackApp",
+		Cluster:        "default",
+		NameSpaceNames: []string{"good", "bad"},
+		CacheDir:       dir,
+		IP:             serv.URL,
+	})
+	for _, namespace := range []string{"good", "bad"} {
+		entry := &CacheEntry{Namespace: namespace, ReleaseKey: "1", Configurations: map[string]interface{}{"key": "stale"}}
+		if err := c.store.Save(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.preload(context.Background()); err != nil {
+		t.Errorf("preload should fall back to local file, got:%v", err)
+	}
+	// bad namespace doesn't wipe good one
+	if v, _ := c.GetStringWithNamespace("good", "key"); v != "fresh" {
+		t.Errorf("good should be fresh, got:%v", v)
+	}
+	if v, _ := c.GetStringWithNamespace("bad", "key"); v != "stale" {
+		t.Errorf("bad should be loaded from local file, got:%v", v)
+	}
+	if status, _ := c.GetNamespaceStatus("bad"); status.Source != SourceCache || status.ReleaseKey != "1" {
+		t.Errorf("unexpected status of bad:%+v", status)
+	}
+
+	if err := c.store.Delete("bad"); err != nil {
+		t.Fatal(err)
+	}
+	c = NewClient(c.conf)
+	if err := c.preload(context.Background()); err == nil {
+		t.Errorf("preload should fail without local file")
+	}
+}
+
+func TestPropertiesNamespace(t *testing.T) {
+	c := NewClient(&Conf{AppID: "PropertiesApp", CacheDir: "/tmp/agollo"})
+	c.handleResult(&result{
+		NamespaceName:  "foo.properties",
+		Configurations: map[string]interface{}{"content": "a.b=c\nport = 8080\n# comment\nname:agollo"},
+	})
+
+	if v, ok := c.GetStringWithNamespace("foo.properties", "a.b"); !ok || v != "c" {
+		t.Errorf("a.b should be flattened into cache, got:%v %v", v, ok)
+	}
+
+	if content, ok := c.GetNamespaceContent("foo.properties"); !ok || content == "" {
+		t.Errorf("content should be kept, got:%v %v", content, ok)
+	}
+
+	var val struct {
+		Port int
+		Name string
+	}
+	if err := c.GetNamespaceVal("foo.properties", &val); err != nil {
+		t.Fatal(err)
+	}
+	if val.Port != 8080 || val.Name != "agollo" {
+		t.Errorf("unexpected val:%+v", val)
+	}
+}
+
+func TestPropertiesNamespaceKeyValues(t *testing.T) {
+	c := NewClient(&Conf{AppID: "PropertiesApp", CacheDir: "/tmp/agollo"})
+	c.handleResult(&result{
+		NamespaceName:  "kv.properties",
+		Configurations: map[string]interface{}{"a.b": "c", "greeting": "hello world"},
+	})
+
+	if v, ok := c.GetStringWithNamespace("kv.properties", "a.b"); !ok || v != "c" {
+		t.Errorf("a.b expected:c got:%v %v", v, ok)
+	}
+	if v, ok := c.GetStringWithNamespace("kv.properties", "greeting"); !ok || v != "hello world" {
+		t.Errorf("greeting expected:hello world got:%v %v", v, ok)
+	}
+	if keys := c.GetAllKeys("kv.properties"); len(keys) != 2 {
+		t.Errorf("keys should be kept as is, got:%v", keys)
+	}
+
+	var val map[string]interface{}
+	if err := c.GetNamespaceVal("kv.properties", &val); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("namespace without content should fail with ErrKeyNotFound, got:%v", err)
+	}
+}
+
+type csvParser struct{}
+
+func (p *csvParser) Parse(configContent interface{}) (map[string]interface{}, error) {
+	content, ok := configContent.(string)
+	if !ok {
+		return nil, nil
+	}
+	ret := map[string]interface{}{}
+	for i, v := range strings.Split(content, ",") {
+		ret[strconv.Itoa(i)] = v
+	}
+	return ret, nil
+}
+
+func (p *csvParser) GetParserType() string {
+	return "csv"
+}
+
+func (p *csvParser) Unmarshal(data []byte, val interface{}) error {
+	*(val.(*[]string)) = strings.Split(string(data), ",")
+	return nil
+}
+
+func TestRegisteredParser(t *testing.T) {
+	parse.Register("csv", &csvParser{})
+
+	c := NewClient(&Conf{AppID: "ParserApp", CacheDir: "/tmp/agollo"})
+	c.handleResult(&result{
+		NamespaceName:  "list.csv",
+		Configurations: map[string]interface{}{"content": "a,b"},
+	})
+
+	if v, ok := c.GetStringWithNamespace("list.csv", "1"); !ok || v != "b" {
+		t.Errorf("content should be parsed by registered parser, got:%v %v", v, ok)
+	}
+
+	var val []string
+	if err := c.GetNamespaceVal("list.csv", &val); err != nil || len(val) != 2 {
+		t.Errorf("content should be unmarshaled by registered parser, got:%v %v", val, err)
+	}
+}
+
+func TestFileNamespaceKeys(t *testing.T) {
+	c := NewClient(&Conf{AppID: "FileApp", CacheDir: "/tmp/agollo"})
+
+	var tcs = []struct {
+		namespace string
+		content   string
+	}{
+		{"service.yaml", "maxConns: 10\ndb:\n  Host: localhost\nlist:\n  - name: a\n  - name: b\n"},
+		{"service.yml", "maxConns: 10\ndb:\n  Host: localhost\nlist:\n  - name: a\n  - name: b\n"},
+		{"service.json", `{"maxConns": 10, "db": {"Host": "localhost"}, "list": [{"name": "a"}, {"name": "b"}]}`},
+	}
+
+	for _, tc := range tcs {
+		c.handleResult(&result{
+			NamespaceName:  tc.namespace,
+			Configurations: map[string]interface{}{"content": tc.content},
+		})
+
+		if _, ok := c.mustGetCache(tc.namespace).get("maxConns"); !ok {
+			t.Errorf("%s: key case should be kept", tc.namespace)
+		}
+		if v, ok := c.GetStringWithNamespace(tc.namespace, "db.Host"); !ok || v != "localhost" {
+			t.Errorf("%s: nested key should be reachable by dotted path, got:%v %v", tc.namespace, v, ok)
+		}
+		if v, ok := c.mustGetCache(tc.namespace).get("db"); !ok || !reflect.DeepEqual(v, map[string]interface{}{"Host": "localhost"}) {
+			t.Errorf("%s: subtree should be reachable, got:%v %v", tc.namespace, v, ok)
+		}
+		if v, ok := c.GetStringWithNamespace(tc.namespace, "list[1].name"); !ok || v != "b" {
+			t.Errorf("%s: array element should be reachable by index, got:%v %v", tc.namespace, v, ok)
+		}
+
+		ce := c.handleResult(&result{
+			NamespaceName:  tc.namespace,
+			Configurations: map[string]interface{}{"content": strings.Replace(tc.content, "localhost", "remotehost", 1)},
+		})
+		if ce == nil || ce.Changes["db.Host"] == nil || ce.Changes["db"] == nil || ce.Changes["list"] != nil {
+			t.Errorf("%s: unexpected change event:%+v", tc.namespace, ce)
+		}
+	}
+}
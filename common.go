@@ -26,20 +26,27 @@ func toIP4(addr net.Addr) net.IP {
 	return nil
 }
 
-func notificationURL(conf *Conf, notifications string) string {
-	return fmt.Sprintf("http://%s/notifications/v2?appId=%s&cluster=%s&notifications=%s",
-		conf.IP,
+func notificationURL(server string, conf *Conf, notifications string) string {
+	return fmt.Sprintf("%s/notifications/v2?appId=%s&cluster=%s&notifications=%s",
+		server,
 		url.QueryEscape(conf.AppID),
 		url.QueryEscape(conf.Cluster),
 		url.QueryEscape(notifications))
 }
 
-func configURL(conf *Conf, namespace, releaseKey string) string {
-	return fmt.Sprintf("http://%s/configs/%s/%s/%s?releaseKey=%s&ip=%s",
-		conf.IP,
+func configURL(server string, conf *Conf, namespace, releaseKey string) string {
+	return fmt.Sprintf("%s/configs/%s/%s/%s?releaseKey=%s&ip=%s",
+		server,
 		url.QueryEscape(conf.AppID),
 		url.QueryEscape(conf.Cluster),
 		url.QueryEscape(namespace),
 		releaseKey,
 		getLocalIP())
 }
+
+func servicesURL(meta string, conf *Conf) string {
+	return fmt.Sprintf("%s/services/config?appId=%s&ip=%s",
+		meta,
+		url.QueryEscape(conf.AppID),
+		getLocalIP())
+}
@@ -14,6 +14,7 @@ func TestLocalIp(t *testing.T) {
 
 func TestNotificationURL(t *testing.T) {
 	target := notificationURL(
+		"http://127.0.0.1:8080",
 		&Conf{
 			IP:      "127.0.0.1:8080",
 			AppID:   "SampleApp",
@@ -25,8 +26,17 @@ func TestNotificationURL(t *testing.T) {
 	}
 }
 
+func TestServicesURL(t *testing.T) {
+	target := servicesURL("http://127.0.0.1:8080", &Conf{AppID: "SampleApp"})
+	_, err := url.Parse(target)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
 func TestConfigURL(t *testing.T) {
 	target := configURL(
+		"http://127.0.0.1:8080",
 		&Conf{
 			IP:      "127.0.0.1:8080",
 			AppID:   "SampleApp",
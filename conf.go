@@ -3,6 +3,7 @@ package agollo
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 )
 
@@ -12,7 +13,51 @@ type Conf struct {
 	Cluster        string   `json:"cluster,omitempty"`
 	NameSpaceNames []string `json:"namespaceNames,omitempty"`
 	CacheDir       string   `json:"cacheDir,omitempty"`
-	IP             string   `json:"ip,omitempty"`
+	// IP is the address of config service, multiple addresses can be
+	// separated by comma, e.g. "localhost:8080" or "https://apollo.example.com",
+	// http is used if no scheme is given
+	IP string `json:"ip,omitempty"`
+	// MetaAddr is the address of meta server, config service instances will
+	// be discovered from it when it's not empty
+	MetaAddr string `json:"metaAddr,omitempty"`
+	// HTTPClient is used as a template of all http clients, its Timeout is
+	// always overridden by agollo
+	HTTPClient *http.Client `json:"-"`
+	// Transport overrides the Transport of HTTPClient if given
+	Transport http.RoundTripper `json:"-"`
+	// Backoff controls poll delay after failures, DefaultBackoffPolicy is
+	// used if nil
+	Backoff *BackoffPolicy `json:"backoff,omitempty"`
+	// Secret is the access key secret of the app, requests will be signed
+	// with it when it's not empty
+	Secret string `json:"secret,omitempty"`
+	// Chains define lookup chains by name, see Client.SetChain
+	Chains map[string][]string `json:"chains,omitempty"`
+	// Interpolate resolve placeholders in values, e.g. ${key},
+	// ${key:default}, ${namespace::key}, keys not found in apollo are looked
+	// up in environment variables
+	Interpolate bool `json:"interpolate,omitempty"`
+	// ObserverQueueSize is the size of change event queue of each observer,
+	// 32 is used if not set
+	ObserverQueueSize int `json:"observerQueueSize,omitempty"`
+	// ObserverOverflow decide what to do when queue of an observer is full,
+	// events are coalesced by default, blocking is opt-in with OverflowBlock
+	ObserverOverflow OverflowPolicy `json:"observerOverflow,omitempty"`
+	// CacheStore save namespaces fetched from apollo, files in
+	// {CacheDir}/{AppID}_{Cluster} are used if nil
+	CacheStore CacheStore `json:"-"`
+	// CacheKey encrypt files of the default CacheStore with AES-GCM, it
+	// should be 16, 24 or 32 bytes
+	CacheKey []byte `json:"cacheKey,omitempty"`
+	// CacheKeyProvider is called for the key instead of CacheKey if given,
+	// e.g. to read it from a KMS
+	CacheKeyProvider func() ([]byte, error) `json:"-"`
+	// Offline skip apollo, namespaces are loaded from OfflineDir, or
+	// CacheStore if it's empty, until Client.GoOnline is called
+	Offline bool `json:"offline,omitempty"`
+	// OfflineDir contains namespace files for Offline mode, e.g. a copy of
+	// cache dir, or files like application.properties and app.yaml
+	OfflineDir string `json:"offlineDir,omitempty"`
 }
 
 // NewConf create Conf from file
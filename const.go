@@ -14,4 +14,15 @@ const (
 	longPollTimeout       = time.Second * 90
 	queryTimeout          = time.Second * 2
 	defaultNotificationID = -1
+
+	// metaRefreshInterval is how often config service instances are
+	// discovered again from meta server
+	metaRefreshInterval = time.Minute * 5
+	// serverCooldown is how long a failed config service instance is
+	// avoided before being tried again
+	serverCooldown = time.Minute
+
+	// defaultObserverQueueSize is the queue size of each observer when
+	// conf.ObserverQueueSize is not set
+	defaultObserverQueueSize = 32
 )
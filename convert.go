@@ -0,0 +1,467 @@
+package agollo
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+
+	// TimeLayouts are tried in order when converting string into time.Time
+	TimeLayouts = []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+		time.RFC1123Z,
+		time.RFC1123,
+	}
+)
+
+// mismatch report that val can never be converted into typ, unlike parse
+// errors of malformed strings
+func mismatch(val interface{}, typ string) error {
+	return fmt.Errorf("%w: cannot convert %T to %s", ErrTypeMismatch, val, typ)
+}
+
+// toString convert scalar value into string
+func toString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	return "", mismatch(val, "string")
+}
+
+func toInt64(val interface{}) (int64, error) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return uint64ToInt64(uint64(v))
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return uint64ToInt64(v)
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		return floatToInt64(v)
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 0, 64)
+	}
+	return 0, mismatch(val, "int")
+}
+
+func uint64ToInt64(u uint64) (int64, error) {
+	if u > math.MaxInt64 {
+		return 0, fmt.Errorf("%d overflows int64", u)
+	}
+	return int64(u), nil
+}
+
+func floatToInt64(f float64) (int64, error) {
+	// float64(math.MaxInt64) is rounded up to 2^63
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("%v overflows int64", f)
+	}
+	if f != float64(int64(f)) {
+		return 0, fmt.Errorf("%v is not an integer", f)
+	}
+	return int64(f), nil
+}
+
+func toUint64(val interface{}) (uint64, error) {
+	switch v := val.(type) {
+	case uint:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case string:
+		return strconv.ParseUint(strings.TrimSpace(v), 0, 64)
+	}
+	i, err := toInt64(val)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("%d is negative", i)
+	}
+	return uint64(i), nil
+}
+
+func toUint(val interface{}) (uint, error) {
+	u, err := toUint64(val)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(uint(u)) != u {
+		return 0, fmt.Errorf("%d overflows uint", u)
+	}
+	return uint(u), nil
+}
+
+func toFloat64(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case uint:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	i, err := toInt64(val)
+	if err != nil {
+		return 0, mismatch(val, "float")
+	}
+	return float64(i), nil
+}
+
+func toBool(val interface{}) (bool, error) {
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	}
+	i, err := toInt64(val)
+	if err != nil {
+		return false, mismatch(val, "bool")
+	}
+	return i != 0, nil
+}
+
+// toDuration convert value into time.Duration, numbers are treated as nanoseconds
+func toDuration(val interface{}) (time.Duration, error) {
+	if s, ok := val.(string); ok {
+		s = strings.TrimSpace(s)
+		if strings.ContainsAny(s, "nsuµmh") {
+			return time.ParseDuration(s)
+		}
+		val = s
+	}
+	i, err := toInt64(val)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert %v to duration: %w", val, err)
+	}
+	return time.Duration(i), nil
+}
+
+func toInt(val interface{}) (int, error) {
+	i, err := toInt64(val)
+	if err != nil {
+		return 0, err
+	}
+	if int64(int(i)) != i {
+		return 0, fmt.Errorf("%d overflows int", i)
+	}
+	return int(i), nil
+}
+
+func toFloat32(val interface{}) (float32, error) {
+	f, err := toFloat64(val)
+	if err != nil {
+		return 0, err
+	}
+	if reflect.Zero(reflect.TypeOf(float32(0))).OverflowFloat(f) {
+		return 0, fmt.Errorf("%v overflows float32", f)
+	}
+	return float32(f), nil
+}
+
+// toTime convert value into time.Time, strings are parsed by TimeLayouts,
+// numbers are treated as unix seconds
+func toTime(val interface{}) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		for _, layout := range TimeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse %q as time", v)
+		}
+	}
+	i, err := toInt64(val)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot convert %v to time: %w", val, err)
+	}
+	return time.Unix(i, 0), nil
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   uint64
+}{
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+	{"kb", 1 << 10}, {"mb", 1 << 20}, {"gb", 1 << 30}, {"tb", 1 << 40},
+	{"k", 1 << 10}, {"m", 1 << 20}, {"g", 1 << 30}, {"t", 1 << 40},
+	{"b", 1},
+}
+
+// toSizeInBytes convert value like "10MB" or "512k" into bytes, units are
+// case-insensitive and based on 1024, numbers without unit are bytes
+func toSizeInBytes(val interface{}) (uint64, error) {
+	s, ok := val.(string)
+	if !ok {
+		return toUint64(val)
+	}
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			num := strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil || f < 0 {
+				return 0, fmt.Errorf("cannot parse %q as size", val)
+			}
+			return uint64(f * float64(unit.size)), nil
+		}
+	}
+	return toUint64(s)
+}
+
+// toStringMapString convert sub tree of file namespaces or string like
+// "k1=v1,k2=v2" into map[string]string
+func toStringMapString(val interface{}) (map[string]string, error) {
+	switch v := val.(type) {
+	case map[string]string:
+		return v, nil
+	case map[string]interface{}:
+		ret := make(map[string]string, len(v))
+		for k, item := range v {
+			s, err := toString(item)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", k, err)
+			}
+			ret[k] = s
+		}
+		return ret, nil
+	case string:
+		return splitMap(v)
+	}
+	return nil, mismatch(val, "map")
+}
+
+// toList convert list of file namespaces or comma separated string into items
+func toList(val interface{}) []interface{} {
+	switch v := val.(type) {
+	case []interface{}:
+		return v
+	case string:
+		var items []interface{}
+		for _, item := range splitList(v) {
+			items = append(items, item)
+		}
+		return items
+	}
+	return []interface{}{val}
+}
+
+func toStringSlice(val interface{}) ([]string, error) {
+	items := toList(val)
+	ret := make([]string, len(items))
+	for i, item := range items {
+		s, err := toString(item)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		ret[i] = s
+	}
+	return ret, nil
+}
+
+func toIntSlice(val interface{}) ([]int, error) {
+	items := toList(val)
+	ret := make([]int, len(items))
+	for i, item := range items {
+		v, err := toInt(item)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		ret[i] = v
+	}
+	return ret, nil
+}
+
+// convertTo convert val into a value of typ, scalars, time.Duration,
+// time.Time, slices and maps with string key are supported
+func convertTo(val interface{}, typ reflect.Type) (reflect.Value, error) {
+	switch typ.Kind() {
+	case reflect.Slice:
+		items := toList(val)
+		ret := reflect.MakeSlice(typ, len(items), len(items))
+		for i, item := range items {
+			cv, err := convertTo(item, typ.Elem())
+			if err != nil {
+				return ret, fmt.Errorf("index %d: %w", i, err)
+			}
+			ret.Index(i).Set(cv)
+		}
+		return ret, nil
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			break
+		}
+		var items map[string]interface{}
+		switch v := val.(type) {
+		case map[string]interface{}:
+			items = v
+		case string:
+			kv, err := splitMap(v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			items = make(map[string]interface{}, len(kv))
+			for k, item := range kv {
+				items[k] = item
+			}
+		default:
+			return reflect.Value{}, mismatch(val, typ.String())
+		}
+		ret := reflect.MakeMapWithSize(typ, len(items))
+		for k, item := range items {
+			cv, err := convertTo(item, typ.Elem())
+			if err != nil {
+				return ret, fmt.Errorf("key %s: %w", k, err)
+			}
+			ret.SetMapIndex(reflect.ValueOf(k).Convert(typ.Key()), cv)
+		}
+		return ret, nil
+	}
+	return convertScalar(val, typ)
+}
+
+// convertScalar convert val into a value of typ, typ should be a scalar type,
+// time.Duration or time.Time
+func convertScalar(val interface{}, typ reflect.Type) (reflect.Value, error) {
+	ret := reflect.New(typ).Elem()
+
+	switch typ {
+	case durationType:
+		d, err := toDuration(val)
+		if err != nil {
+			return ret, err
+		}
+		ret.SetInt(int64(d))
+		return ret, nil
+	case timeType:
+		t, err := toTime(val)
+		if err != nil {
+			return ret, err
+		}
+		ret.Set(reflect.ValueOf(t))
+		return ret, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		s, err := toString(val)
+		if err != nil {
+			return ret, err
+		}
+		ret.SetString(s)
+	case reflect.Bool:
+		b, err := toBool(val)
+		if err != nil {
+			return ret, err
+		}
+		ret.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(val)
+		if err != nil {
+			return ret, err
+		}
+		if ret.OverflowInt(i) {
+			return ret, fmt.Errorf("%d overflows %s", i, typ)
+		}
+		ret.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := toUint64(val)
+		if err != nil {
+			return ret, err
+		}
+		if ret.OverflowUint(u) {
+			return ret, fmt.Errorf("%d overflows %s", u, typ)
+		}
+		ret.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(val)
+		if err != nil {
+			return ret, err
+		}
+		if ret.OverflowFloat(f) {
+			return ret, fmt.Errorf("%v overflows %s", f, typ)
+		}
+		ret.SetFloat(f)
+	case reflect.Interface:
+		if val != nil {
+			ret.Set(reflect.ValueOf(val))
+		}
+	default:
+		return ret, fmt.Errorf("%w: unsupported type %s", ErrTypeMismatch, typ)
+	}
+	return ret, nil
+}
+
+// splitList split comma separated string, elements are trimmed
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	strs := strings.Split(s, ",")
+	for i := range strs {
+		strs[i] = strings.TrimSpace(strs[i])
+	}
+	return strs
+}
+
+// splitMap split string like "k1=v1,k2=v2" or "k1:v1,k2:v2"
+func splitMap(s string) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, item := range splitList(s) {
+		i := strings.IndexAny(item, "=:")
+		if i < 0 {
+			return nil, fmt.Errorf("%q is not a key value pair", item)
+		}
+		ret[strings.TrimSpace(item[:i])] = strings.TrimSpace(item[i+1:])
+	}
+	return ret, nil
+}
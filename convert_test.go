@@ -0,0 +1,136 @@
+package agollo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConvertScalar(t *testing.T) {
+	var tcs = []struct {
+		val      interface{}
+		typ      reflect.Type
+		expected interface{}
+		wantErr  bool
+	}{
+		{"42", reflect.TypeOf(0), 42, false},
+		{42.0, reflect.TypeOf(0), 42, false},
+		{42.5, reflect.TypeOf(0), 0, true},
+		{"0x10", reflect.TypeOf(int64(0)), int64(16), false},
+		{"300", reflect.TypeOf(int8(0)), int8(0), true},
+		{uint64(math.MaxUint64), reflect.TypeOf(int64(0)), int64(0), true},
+		{uint64(math.MaxInt64), reflect.TypeOf(int64(0)), int64(math.MaxInt64), false},
+		{1e19, reflect.TypeOf(int64(0)), int64(0), true},
+		{-1e19, reflect.TypeOf(int64(0)), int64(0), true},
+		{math.Inf(1), reflect.TypeOf(0), 0, true},
+		{uint64(math.MaxUint64), reflect.TypeOf(uint64(0)), uint64(math.MaxUint64), false},
+		{"7", reflect.TypeOf(uint(0)), uint(7), false},
+		{-1, reflect.TypeOf(uint(0)), uint(0), true},
+		{"1.5", reflect.TypeOf(float32(0)), float32(1.5), false},
+		{3, reflect.TypeOf(float64(0)), float64(3), false},
+		{"true", reflect.TypeOf(false), true, false},
+		{1, reflect.TypeOf(false), true, false},
+		{"yes", reflect.TypeOf(false), false, true},
+		{1.5, reflect.TypeOf(""), "1.5", false},
+		{true, reflect.TypeOf(""), "true", false},
+		{"1m30s", durationType, time.Minute + time.Second*30, false},
+		{"100", durationType, time.Duration(100), false},
+		{"abc", durationType, time.Duration(0), true},
+		{[]interface{}{1}, reflect.TypeOf(""), "", true},
+	}
+
+	for i, tc := range tcs {
+		v, err := convertScalar(tc.val, tc.typ)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test %d: wantErr:%v got:%v", i+1, tc.wantErr, err)
+			continue
+		}
+		if err == nil && v.Interface() != tc.expected {
+			t.Errorf("test %d: expected:%v got:%v", i+1, tc.expected, v.Interface())
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	if strs := splitList(" a, b ,c"); !reflect.DeepEqual(strs, []string{"a", "b", "c"}) {
+		t.Errorf("unexpected list:%v", strs)
+	}
+	if strs := splitList(" "); strs != nil {
+		t.Errorf("blank string should be an empty list, got:%v", strs)
+	}
+
+	m, err := splitMap("a=1, b:2")
+	if err != nil || !reflect.DeepEqual(m, map[string]string{"a": "1", "b": "2"}) {
+		t.Errorf("unexpected map:%v %v", m, err)
+	}
+	if _, err := splitMap("a"); err == nil {
+		t.Errorf("bad pair should fail")
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	var tcs = []struct {
+		val      interface{}
+		typ      reflect.Type
+		expected interface{}
+		wantErr  bool
+	}{
+		{"1,2, 3", reflect.TypeOf([]int{}), []int{1, 2, 3}, false},
+		{[]interface{}{"a", 1}, reflect.TypeOf([]string{}), []string{"a", "1"}, false},
+		{"1,x", reflect.TypeOf([]int{}), nil, true},
+		{"a=1,b=2", reflect.TypeOf(map[string]int{}), map[string]int{"a": 1, "b": 2}, false},
+		{map[string]interface{}{"a": "1s"}, reflect.TypeOf(map[string]time.Duration{}), map[string]time.Duration{"a": time.Second}, false},
+		{"2021-03-04", timeType, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), false},
+		{"2021-03-04T05:06:07Z", timeType, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), false},
+		{"2021/03/04", timeType, nil, true},
+		{"42", reflect.TypeOf(int64(0)), int64(42), false},
+	}
+
+	for i, tc := range tcs {
+		v, err := convertTo(tc.val, tc.typ)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test %d: wantErr:%v got:%v", i+1, tc.wantErr, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(v.Interface(), tc.expected) {
+			t.Errorf("test %d: expected:%v got:%v", i+1, tc.expected, v.Interface())
+		}
+	}
+}
+
+func TestToTimeUnix(t *testing.T) {
+	ts, err := toTime("1600000000")
+	if err != nil || ts.Unix() != 1600000000 {
+		t.Errorf("unexpected time:%v %v", ts, err)
+	}
+}
+
+func TestToSizeInBytes(t *testing.T) {
+	var tcs = []struct {
+		val      interface{}
+		expected uint64
+		wantErr  bool
+	}{
+		{"512", 512, false},
+		{512, 512, false},
+		{"64k", 64 << 10, false},
+		{"10MB", 10 << 20, false},
+		{"1.5 GiB", 3 << 29, false},
+		{"2t", 2 << 40, false},
+		{"100b", 100, false},
+		{"-1k", 0, true},
+		{"ten mb", 0, true},
+	}
+
+	for i, tc := range tcs {
+		v, err := toSizeInBytes(tc.val)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test %d: wantErr:%v got:%v", i+1, tc.wantErr, err)
+			continue
+		}
+		if v != tc.expected {
+			t.Errorf("test %d: expected:%v got:%v", i+1, tc.expected, v)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package agollo
+
+import (
+	"context"
+	"sync"
+)
+
+// dispatcher deliver change events to observers in a single goroutine
+type dispatcher struct {
+	// lock guard updates, which is closed when client stops
+	lock    sync.RWMutex
+	updates chan *ChangeEvent
+	stopped bool
+	done    chan struct{}
+	// quit is closed on stop to release blocked senders, updates is closed
+	// after all senders returned
+	quit    chan struct{}
+	senders sync.WaitGroup
+
+	// pending count events sent after start but not yet handled, idle is
+	// closed when pending drops to zero
+	pendingLock sync.Mutex
+	pending     int
+	idle        chan struct{}
+}
+
+// watch return the update channel, a closed channel is returned after stop
+func (d *dispatcher) watch() chan *ChangeEvent {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.updates == nil {
+		d.updates = make(chan *ChangeEvent, 32)
+		d.quit = make(chan struct{})
+		if d.stopped {
+			close(d.updates)
+			close(d.quit)
+		}
+	}
+	return d.updates
+}
+
+// send push event to update channel, events are dropped if nobody watches,
+// ctx is done or dispatcher stops. The lock is not held while blocking, so
+// start can't be blocked by a full channel
+func (d *dispatcher) send(ctx context.Context, event *ChangeEvent) {
+	d.lock.RLock()
+	if d.updates == nil || d.stopped {
+		d.lock.RUnlock()
+		return
+	}
+	updates, quit := d.updates, d.quit
+	d.senders.Add(1)
+	d.lock.RUnlock()
+	defer d.senders.Done()
+
+	d.addPending(1)
+	select {
+	case <-ctx.Done():
+		d.addPending(-1)
+	case <-quit:
+		d.addPending(-1)
+	case updates <- event:
+	}
+}
+
+// start consume update channel and call handle for every event, it's a no-op
+// if already started
+func (d *dispatcher) start(handle func(event *ChangeEvent)) {
+	updates := d.watch()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.done != nil {
+		return
+	}
+	d.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		for event := range updates {
+			handle(event)
+			d.addPending(-1)
+		}
+	}(d.done)
+}
+
+// stop release blocked senders, close update channel, and wait until events
+// already in the channel are handled or ctx is done
+func (d *dispatcher) stop(ctx context.Context) error {
+	d.lock.Lock()
+	stopping := !d.stopped && d.updates != nil
+	d.stopped = true
+	updates, quit, done := d.updates, d.quit, d.done
+	d.lock.Unlock()
+
+	if stopping {
+		// no sender is added once stopped is set
+		close(quit)
+		d.senders.Wait()
+		close(updates)
+	}
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wait block until all sent events are handled, or ctx is done, it return
+// at once if the dispatcher is not started
+func (d *dispatcher) wait(ctx context.Context) error {
+	d.lock.RLock()
+	started := d.done != nil
+	d.lock.RUnlock()
+	if !started {
+		return nil
+	}
+
+	d.pendingLock.Lock()
+	idle := d.idle
+	d.pendingLock.Unlock()
+
+	if idle == nil {
+		return nil
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *dispatcher) addPending(delta int) {
+	d.pendingLock.Lock()
+	defer d.pendingLock.Unlock()
+
+	if delta == 0 {
+		return
+	}
+	if d.pending == 0 && delta > 0 {
+		d.idle = make(chan struct{})
+	}
+	d.pending += delta
+	if d.pending == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
@@ -0,0 +1,163 @@
+package agollo
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingObserver struct {
+	count   int32
+	blocker chan struct{}
+}
+
+func (o *countingObserver) HandleChangeEvent(event *ChangeEvent) {
+	if o.blocker != nil {
+		<-o.blocker
+	}
+	atomic.AddInt32(&o.count, 1)
+}
+
+func TestDispatcherLifecycle(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	c := NewClient(&Conf{AppID: "DispatchApp", CacheDir: "/tmp/agollo"})
+	ob := &countingObserver{}
+	c.registerObserver(ob)
+
+	c.StartWatchUpdate()
+	c.StartWatchUpdate()
+
+	for i := 0; i < 3; i++ {
+		c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.WaitDispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&ob.count); n != 3 {
+		t.Errorf("every event should be handled once, got:%d", n)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// deliveries after stop are dropped without blocking
+	c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace})
+	if _, ok := <-c.WatchUpdate(); ok {
+		t.Errorf("update channel should be closed after stop")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutines leaked, baseline:%d now:%d\n%s", baseline, runtime.NumGoroutine(), buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestWaitDispatch(t *testing.T) {
+	c := NewClient(&Conf{AppID: "DispatchApp", CacheDir: "/tmp/agollo"})
+	ob := &countingObserver{blocker: make(chan struct{})}
+	c.registerObserver(ob)
+
+	// events delivered before start are handled too
+	c.WatchUpdate()
+	c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace})
+	c.StartWatchUpdate()
+	c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if err := c.WaitDispatch(ctx); err != context.DeadlineExceeded {
+		t.Errorf("WaitDispatch should wait for blocked observer, got:%v", err)
+	}
+
+	close(ob.blocker)
+	if err := c.WaitDispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&ob.count); n != 2 {
+		t.Errorf("expected 2 events handled, got:%d", n)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartWatchUpdateWithFullChannel(t *testing.T) {
+	c := NewClient(&Conf{AppID: "DispatchApp", CacheDir: "/tmp/agollo", ObserverOverflow: OverflowBlock})
+	ob := &countingObserver{}
+	c.registerObserver(ob)
+
+	// more events than the channel holds are delivered before start, the
+	// rest senders block until the dispatcher consumes
+	n := cap(c.WatchUpdate()) + 8
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i < n; i++ {
+			c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace})
+		}
+	}()
+	for len(c.WatchUpdate()) < cap(c.WatchUpdate()) {
+		time.Sleep(time.Millisecond)
+	}
+
+	started := make(chan struct{})
+	go func() {
+		c.StartWatchUpdate()
+		close(started)
+	}()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("StartWatchUpdate should not be blocked by a full channel")
+	}
+	<-sent
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.WaitDispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&ob.count); int(got) != n {
+		t.Errorf("expected %d events handled, got:%d", n, got)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStopReleaseBlockedSender(t *testing.T) {
+	var d dispatcher
+	d.watch()
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i <= cap(d.updates); i++ {
+			d.send(context.Background(), &ChangeEvent{})
+		}
+	}()
+	for len(d.updates) < cap(d.updates) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := d.stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("blocked sender should be released by stop")
+	}
+}
@@ -0,0 +1,291 @@
+package agollo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZhengHe-MD/agollo/v4/parse"
+)
+
+const (
+	dumpMetaSuffix = ".meta.json"
+	dumpContentKey = "content"
+	// dumpFilePerm is restrictive, since namespaces may hold secrets
+	dumpFilePerm = 0600
+	dumpDirPerm  = 0700
+)
+
+// encryptedMagic is the header of encrypted files, followed by nonce and
+// sealed data
+var encryptedMagic = []byte("AGOLLO-AES-GCM\n")
+
+func init() {
+	// values of file namespaces in legacy gob dumps may be maps or slices
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// fileCacheStore save entry of a namespace as {namespace}.meta.json in dir,
+// content of file namespaces is saved as is in {namespace} besides it
+type fileCacheStore struct {
+	dir string
+	// key return AES key to encrypt files, files are plain if it's nil
+	key func() ([]byte, error)
+}
+
+// NewFileCacheStore create a CacheStore saving entries in dir, it's the
+// default one, with dir {CacheDir}/{AppID}_{Cluster}
+func NewFileCacheStore(dir string) CacheStore {
+	return &fileCacheStore{dir: dir}
+}
+
+// NewEncryptedFileCacheStore is like NewFileCacheStore, but files are
+// encrypted with AES-GCM, key should return a key of 16, 24 or 32 bytes
+func NewEncryptedFileCacheStore(dir string, key func() ([]byte, error)) CacheStore {
+	return &fileCacheStore{dir: dir, key: key}
+}
+
+// newDefaultCacheStore create file store in dir, encrypted if conf has a key
+func newDefaultCacheStore(dir string, conf *Conf) CacheStore {
+	switch {
+	case conf.CacheKeyProvider != nil:
+		return NewEncryptedFileCacheStore(dir, conf.CacheKeyProvider)
+	case conf.CacheKey != nil:
+		key := conf.CacheKey
+		return NewEncryptedFileCacheStore(dir, func() ([]byte, error) { return key, nil })
+	}
+	return NewFileCacheStore(dir)
+}
+
+// checkNamespaceName reject namespaces which are not plain file names, so
+// files are never read or written outside of dir
+func checkNamespaceName(namespace string) error {
+	if namespace == "" || namespace == "." || namespace == ".." || strings.ContainsAny(namespace, "/\\\x00") {
+		return fmt.Errorf("invalid namespace name %q", namespace)
+	}
+	return nil
+}
+
+// isFileNamespace tell whether content of namespace is saved in its own file
+func isFileNamespace(namespace string, configurations map[string]interface{}) bool {
+	if parse.GetNamespaceType(namespace) == "" || len(configurations) != 1 {
+		return false
+	}
+	_, ok := configurations[dumpContentKey].(string)
+	return ok
+}
+
+// Save write content file before meta, so meta never refers to content older
+// than itself
+func (s *fileCacheStore) Save(entry *CacheEntry) error {
+	if err := checkNamespaceName(entry.Namespace); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, dumpDirPerm); err != nil {
+		return err
+	}
+
+	meta := *entry
+	if isFileNamespace(entry.Namespace, entry.Configurations) {
+		content := entry.Configurations[dumpContentKey].(string)
+		if err := s.writeFile(s.contentFile(entry.Namespace), []byte(content)); err != nil {
+			return err
+		}
+		meta.Configurations = nil
+	} else {
+		// content of a former file namespace is stale
+		os.Remove(s.contentFile(entry.Namespace))
+	}
+
+	bts, err := json.MarshalIndent(&meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(s.metaFile(entry.Namespace), bts)
+}
+
+func (s *fileCacheStore) Load(namespace string) (*CacheEntry, error) {
+	if err := checkNamespaceName(namespace); err != nil {
+		return nil, err
+	}
+	bts, err := s.readFile(s.metaFile(namespace))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrCacheNotFound, s.metaFile(namespace))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(bts, &entry); err != nil {
+		return nil, err
+	}
+	entry.Namespace = namespace
+
+	if entry.Configurations == nil && entry.Values == nil {
+		content, err := s.readFile(s.contentFile(namespace))
+		if os.IsNotExist(err) {
+			// empty maps are omitted from meta
+			entry.Values = map[string]interface{}{}
+			return &entry, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry.Configurations = map[string]interface{}{dumpContentKey: string(content)}
+	}
+	return &entry, nil
+}
+
+func (s *fileCacheStore) Delete(namespace string) error {
+	if err := checkNamespaceName(namespace); err != nil {
+		return err
+	}
+	if err := os.Remove(s.metaFile(namespace)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(s.contentFile(namespace)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileCacheStore) metaFile(namespace string) string {
+	return filepath.Join(s.dir, namespace+dumpMetaSuffix)
+}
+
+func (s *fileCacheStore) contentFile(namespace string) string {
+	return filepath.Join(s.dir, namespace)
+}
+
+func (s *fileCacheStore) writeFile(name string, data []byte) error {
+	if s.key != nil {
+		aead, err := s.newAEAD()
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		// file name is authenticated, so files can't be swapped
+		sealed := append(append([]byte(nil), encryptedMagic...), nonce...)
+		data = aead.Seal(sealed, nonce, data, []byte(filepath.Base(name)))
+	}
+	return writeFileAtomic(name, data, dumpFilePerm)
+}
+
+// readFile read and decrypt file, errors of decryption wrap ErrDecrypt
+func (s *fileCacheStore) readFile(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := bytes.HasPrefix(data, encryptedMagic)
+	if s.key == nil {
+		if encrypted {
+			return nil, fmt.Errorf("%w: %s is encrypted, but no key is given", ErrDecrypt, name)
+		}
+		return data, nil
+	}
+	if !encrypted {
+		return nil, fmt.Errorf("%w: %s is not encrypted", ErrDecrypt, name)
+	}
+
+	aead, err := s.newAEAD()
+	if err != nil {
+		return nil, err
+	}
+	data = data[len(encryptedMagic):]
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: %s is truncated", ErrDecrypt, name)
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(filepath.Base(name)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDecrypt, name, err)
+	}
+	return plain, nil
+}
+
+func (s *fileCacheStore) newAEAD() (cipher.AEAD, error) {
+	key, err := s.key()
+	if err != nil {
+		return nil, fmt.Errorf("cache key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("cache key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic write data to a temp file, and rename it to name after
+// fsync, so name is either the old or the new content after a crash
+func writeFileAtomic(name string, data []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(filepath.Dir(name), filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// migrateLegacyDump convert the gob dump written by former versions into
+// entries of store, namespaces already in store are kept, even if they fail
+// to load, the gob dump is removed after migration
+func migrateLegacyDump(name string, store CacheStore) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var dumps = make(map[string]map[string]interface{})
+	err = gob.NewDecoder(f).Decode(&dumps)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces []string
+	for namespace, kv := range dumps {
+		if _, err := store.Load(namespace); !errors.Is(err, ErrCacheNotFound) {
+			continue
+		}
+		if err := store.Save(&CacheEntry{Namespace: namespace, Values: kv}); err != nil {
+			return namespaces, err
+		}
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces, os.Remove(name)
+}
@@ -0,0 +1,231 @@
+package agollo
+
+import (
+	"encoding/gob"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agollo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewClient(&Conf{AppID: "DumpApp", Cluster: "default", CacheDir: dir})
+	content := "db:\n  host: localhost\n"
+	c.handleResult(&result{NamespaceName: defaultNamespace, ReleaseKey: "r1", Configurations: map[string]interface{}{"timeout": "1"}})
+	c.handleResult(&result{NamespaceName: "app.yaml", ReleaseKey: "r2", Configurations: map[string]interface{}{"content": content}})
+
+	// content of file namespaces is kept as is
+	bts, err := ioutil.ReadFile(filepath.Join(c.getDumpDir(), "app.yaml"))
+	if err != nil || string(bts) != content {
+		t.Errorf("unexpected content:%q %v", bts, err)
+	}
+	entry, err := c.store.Load("app.yaml")
+	if err != nil || entry.ReleaseKey != "r2" || entry.FetchTime.IsZero() {
+		t.Errorf("unexpected entry:%+v %v", entry, err)
+	}
+
+	// temp files are renamed
+	files, err := ioutil.ReadDir(c.getDumpDir())
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	sort.Strings(names)
+	if err != nil || !reflect.DeepEqual(names, []string{"app.yaml", "app.yaml.meta.json", "application.meta.json"}) {
+		t.Errorf("unexpected files:%v %v", names, err)
+	}
+
+	restore := NewClient(&Conf{AppID: "DumpApp", Cluster: "default", CacheDir: dir})
+	for _, namespace := range []string{"app.yaml", defaultNamespace} {
+		if err := restore.loadLocal(namespace); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, ok := restore.GetStringWithNamespace("app.yaml", "db.host"); !ok || v != "localhost" {
+		t.Errorf("db.host expected:localhost got:%v %v", v, ok)
+	}
+	if status, _ := restore.GetNamespaceStatus(defaultNamespace); status.Source != SourceCache || status.ReleaseKey != "r1" {
+		t.Errorf("unexpected status:%+v", status)
+	}
+
+	if err := restore.loadLocal("null"); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("loading missing namespace should fail with ErrCacheNotFound, got:%v", err)
+	}
+
+	if err := restore.store.Delete("app.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(restore.getDumpDir(), "app.yaml")); !os.IsNotExist(err) {
+		t.Errorf("content should be deleted, got:%v", err)
+	}
+}
+
+func TestMigrateLegacyDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agollo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewClient(&Conf{AppID: "DumpApp", Cluster: "default", CacheDir: dir})
+	f, err := os.Create(c.getDumpFileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dumps := map[string]map[string]interface{}{
+		defaultNamespace: {"key": "val"},
+		"app.yaml":       {"map": map[string]interface{}{"list": []interface{}{"a"}}, "map.list": []interface{}{"a"}},
+	}
+	if err := gob.NewEncoder(f).Encode(&dumps); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	namespaces, err := migrateLegacyDump(c.getDumpFileName(), c.store)
+	if err != nil || len(namespaces) != 2 {
+		t.Fatalf("unexpected migration:%v %v", namespaces, err)
+	}
+	if _, err := os.Stat(c.getDumpFileName()); !os.IsNotExist(err) {
+		t.Errorf("legacy dump should be removed, got:%v", err)
+	}
+
+	for _, namespace := range namespaces {
+		if err := c.loadLocal(namespace); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, ok := c.GetString("key"); !ok || v != "val" {
+		t.Errorf("key expected:val got:%v %v", v, ok)
+	}
+	if v, ok := c.GetStringSliceWithNamespace("app.yaml", "map.list"); !ok || !reflect.DeepEqual(v, []string{"a"}) {
+		t.Errorf("map.list expected:[a] got:%v %v", v, ok)
+	}
+
+	if namespaces, err := migrateLegacyDump(c.getDumpFileName(), c.store); err != nil || namespaces != nil {
+		t.Errorf("nothing to migrate, got:%v %v", namespaces, err)
+	}
+	if _, err := migrateLegacyDump("./testdata/app.properties", c.store); err == nil {
+		t.Errorf("invalid legacy dump should fail")
+	}
+}
+
+func TestEncryptedDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agollo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key := []byte("0123456789abcdef")
+	c := NewClient(&Conf{AppID: "DumpApp", Cluster: "default", CacheDir: dir, CacheKey: key})
+	c.handleResult(&result{NamespaceName: defaultNamespace, ReleaseKey: "r1", Configurations: map[string]interface{}{"password": "secret"}})
+
+	name := filepath.Join(c.getDumpDir(), defaultNamespace+dumpMetaSuffix)
+	bts, err := ioutil.ReadFile(name)
+	if err != nil || strings.Contains(string(bts), "secret") {
+		t.Errorf("file should be encrypted, got:%q %v", bts, err)
+	}
+	if fi, err := os.Stat(name); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("file should be 0600, got:%v %v", fi.Mode(), err)
+	}
+
+	if entry, err := c.store.Load(defaultNamespace); err != nil || entry.Configurations["password"] != "secret" {
+		t.Errorf("unexpected entry:%+v %v", entry, err)
+	}
+
+	wrongKey := NewEncryptedFileCacheStore(c.getDumpDir(), func() ([]byte, error) { return []byte("fedcba9876543210"), nil })
+	if _, err := wrongKey.Load(defaultNamespace); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong key should fail with ErrDecrypt, got:%v", err)
+	}
+	if _, err := NewFileCacheStore(c.getDumpDir()).Load(defaultNamespace); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("missing key should fail with ErrDecrypt, got:%v", err)
+	}
+
+	providerErr := errors.New("kms unavailable")
+	failing := NewEncryptedFileCacheStore(c.getDumpDir(), func() ([]byte, error) { return nil, providerErr })
+	if _, err := failing.Load(defaultNamespace); !errors.Is(err, providerErr) {
+		t.Errorf("provider error should be returned, got:%v", err)
+	}
+
+	if err := NewFileCacheStore(c.getDumpDir()).Save(&CacheEntry{Namespace: "plain"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.store.Load("plain"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("plain file should fail with ErrDecrypt, got:%v", err)
+	}
+}
+
+func TestStartWithWrongCacheKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agollo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := &Conf{
+		AppID:          "DumpApp",
+		Cluster:        "default",
+		NameSpaceNames: []string{defaultNamespace},
+		CacheDir:       dir,
+		CacheKey:       []byte("0123456789abcdef"),
+		IP:             "127.0.0.1:1",
+	}
+	c := NewClient(conf)
+	if err := c.store.Save(&CacheEntry{Namespace: defaultNamespace, Configurations: map[string]interface{}{"key": "val"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	conf.CacheKey = []byte("fedcba9876543210")
+	c = NewClient(conf)
+	err = c.Start()
+	defer c.Stop()
+	if !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Start should fail with ErrDecrypt, got:%v", err)
+	}
+	var nsErr *NamespaceError
+	if !errors.As(err, &nsErr) || nsErr.Namespace != defaultNamespace {
+		t.Errorf("Start should fail with NamespaceError of fetching, got:%v", err)
+	}
+
+	conf.Offline = true
+	if err := NewClient(conf).Start(); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("offline Start should fail with ErrDecrypt, got:%v", err)
+	}
+}
+
+func TestDumpInvalidNamespace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agollo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileCacheStore(filepath.Join(dir, "cache"))
+	for _, namespace := range []string{"../escaped", "a/b", "..", ""} {
+		if err := store.Save(&CacheEntry{Namespace: namespace}); err == nil {
+			t.Errorf("saving %q should fail", namespace)
+		}
+		if _, err := store.Load(namespace); err == nil || errors.Is(err, ErrCacheNotFound) {
+			t.Errorf("loading %q should fail, got:%v", namespace, err)
+		}
+		if err := store.Delete(namespace); err == nil {
+			t.Errorf("deleting %q should fail", namespace)
+		}
+		if _, err := loadOfflineFile(dir, namespace, &Conf{}); err == nil || errors.Is(err, ErrCacheNotFound) {
+			t.Errorf("loading offline %q should fail, got:%v", namespace, err)
+		}
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("nothing should be written, got:%d files", len(files))
+	}
+}
@@ -0,0 +1,143 @@
+package agollo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotModified is returned when config or notifications are not
+	// changed since last request, http status 304
+	ErrNotModified = errors.New("not modified")
+	// ErrNamespaceNotFound is returned when namespace is unknown to apollo,
+	// http status 404
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	// ErrUnauthorized is returned when request is rejected, usually because of
+	// a wrong access key secret, http status 401 or 403
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrServerError is returned when apollo fails, http status 5xx
+	ErrServerError = errors.New("server error")
+
+	// ErrKeyNotFound is returned when key does not exist in namespace
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrTypeMismatch is returned when value can never be converted into the
+	// wanted type, e.g. a yaml list read as int
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrParse is returned when a string value is malformed for the wanted
+	// type, e.g. "abc" read as int
+	ErrParse = errors.New("parse error")
+	// ErrCircularReference is returned when placeholders of a value refer to
+	// the value itself, see Conf.Interpolate
+	ErrCircularReference = errors.New("circular reference")
+
+	// ErrCacheNotFound is returned by CacheStore when namespace is not saved
+	ErrCacheNotFound = errors.New("cache not found")
+	// ErrDecrypt is returned when an encrypted cache file can't be decrypted,
+	// e.g. the key is wrong or missing, see Conf.CacheKey
+	ErrDecrypt = errors.New("decrypt cache failed")
+)
+
+// StatusError is returned when apollo responds with a non-200 status, use
+// errors.Is with ErrNotModified, ErrNamespaceNotFound, ErrUnauthorized and
+// ErrServerError to tell them apart
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s", e.StatusCode, e.URL)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotModified:
+		return e.StatusCode == http.StatusNotModified
+	case ErrNamespaceNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// NamespaceError is returned when syncing a namespace fails
+type NamespaceError struct {
+	Namespace string
+	Err       error
+}
+
+func (e *NamespaceError) Error() string {
+	return fmt.Sprintf("namespace %s: %v", e.Namespace, e.Err)
+}
+
+func (e *NamespaceError) Unwrap() error {
+	return e.Err
+}
+
+// fallbackError is returned when namespace can be loaded neither from apollo
+// nor from local cache, errors.Is matches both errors, e.g. ErrDecrypt
+type fallbackError struct {
+	err   error
+	local error
+}
+
+func (e *fallbackError) Error() string {
+	return fmt.Sprintf("%v, load local cache: %v", e.err, e.local)
+}
+
+func (e *fallbackError) Unwrap() error {
+	return e.err
+}
+
+func (e *fallbackError) Is(target error) bool {
+	return errors.Is(e.local, target)
+}
+
+// GetError is returned by GetXxxE getters, Err is one of ErrKeyNotFound,
+// ErrTypeMismatch, ErrParse and ErrCircularReference, Cause holds details
+type GetError struct {
+	Namespace string
+	Key       string
+	Value     interface{}
+	Err       error
+	Cause     error
+}
+
+func newGetError(namespace, key string, val interface{}, cause error) *GetError {
+	e := &GetError{Namespace: namespace, Key: key, Value: val, Err: ErrParse, Cause: cause}
+	switch {
+	case errors.Is(cause, ErrTypeMismatch):
+		e.Err = ErrTypeMismatch
+	case errors.Is(cause, ErrCircularReference):
+		e.Err = ErrCircularReference
+	}
+	return e
+}
+
+func (e *GetError) Error() string {
+	switch {
+	case e.Cause == nil:
+		return fmt.Sprintf("namespace %s key %s: %v", e.Namespace, e.Key, e.Err)
+	case errors.Is(e.Cause, e.Err):
+		return fmt.Sprintf("namespace %s key %s value %#v: %v", e.Namespace, e.Key, e.Value, e.Cause)
+	}
+	return fmt.Sprintf("namespace %s key %s value %#v: %v: %v", e.Namespace, e.Key, e.Value, e.Err, e.Cause)
+}
+
+func (e *GetError) Unwrap() error {
+	return e.Err
+}
+
+// isServerFailure report whether err means the server is unavailable, and
+// another server should be tried
+func isServerFailure(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return errors.Is(statusErr, ErrServerError)
+	}
+	return err != nil
+}
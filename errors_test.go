@@ -0,0 +1,107 @@
+package agollo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStatusError(t *testing.T) {
+	var tcs = []struct {
+		code     int
+		expected error
+	}{
+		{http.StatusNotModified, ErrNotModified},
+		{http.StatusNotFound, ErrNamespaceNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusInternalServerError, ErrServerError},
+		{http.StatusBadGateway, ErrServerError},
+	}
+
+	for _, tc := range tcs {
+		var err error = &NamespaceError{Namespace: defaultNamespace, Err: &StatusError{URL: "url", StatusCode: tc.code}}
+		for _, target := range []error{ErrNotModified, ErrNamespaceNotFound, ErrUnauthorized, ErrServerError} {
+			if errors.Is(err, target) != (target == tc.expected) {
+				t.Errorf("status %d: errors.Is(%v) should be %v", tc.code, target, target == tc.expected)
+			}
+		}
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != tc.code {
+			t.Errorf("status %d: should be unwrapped as StatusError", tc.code)
+		}
+
+		if isServerFailure(err) != (tc.expected == ErrServerError) {
+			t.Errorf("status %d: isServerFailure should be %v", tc.code, tc.expected == ErrServerError)
+		}
+	}
+
+	if !isServerFailure(errors.New("connection refused")) || isServerFailure(nil) {
+		t.Errorf("unexpected isServerFailure result")
+	}
+}
+
+func TestSyncStatus(t *testing.T) {
+	var status int32 = http.StatusNotModified
+	var requests int32
+	serv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer serv.Close()
+
+	c := NewClient(&Conf{
+		AppID:    "SampleApp",
+		Cluster:  "default",
+		CacheDir: "/tmp/agollo",
+		IP:       serv.URL + "," + serv.URL,
+	})
+
+	if change, err := c.sync(context.Background(), defaultNamespace); change != nil || err != nil {
+		t.Errorf("not modified should be treated as unchanged, got:%v %v", change, err)
+	}
+
+	atomic.StoreInt32(&status, http.StatusNotFound)
+	var nsErr *NamespaceError
+	if _, err := c.sync(context.Background(), defaultNamespace); !errors.Is(err, ErrNamespaceNotFound) || !errors.As(err, &nsErr) || nsErr.Namespace != defaultNamespace {
+		t.Errorf("expected namespace not found, got:%v", err)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	atomic.StoreInt32(&status, http.StatusInternalServerError)
+	if _, err := c.sync(context.Background(), defaultNamespace); !errors.Is(err, ErrServerError) {
+		t.Errorf("expected server error, got:%v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("server error should fail over to all servers, got %d requests", n)
+	}
+}
+
+func TestGetError(t *testing.T) {
+	var tcs = []struct {
+		val      interface{}
+		conv     func(interface{}) error
+		expected error
+	}{
+		{"abc", func(v interface{}) error { _, err := toInt(v); return err }, ErrParse},
+		{[]interface{}{1}, func(v interface{}) error { _, err := toInt(v); return err }, ErrTypeMismatch},
+		{"1,x", func(v interface{}) error { _, err := toIntSlice(v); return err }, ErrParse},
+		{[]interface{}{true, map[string]interface{}{}}, func(v interface{}) error { _, err := toStringSlice(v); return err }, ErrTypeMismatch},
+		{"10 parsecs", func(v interface{}) error { _, err := toDuration(v); return err }, ErrParse},
+		{"300", func(v interface{}) error { _, err := convertScalar(v, reflect.TypeOf(int8(0))); return err }, ErrParse},
+	}
+
+	for i, tc := range tcs {
+		err := newGetError(defaultNamespace, "key", tc.val, tc.conv(tc.val))
+		for _, target := range []error{ErrKeyNotFound, ErrTypeMismatch, ErrParse} {
+			if errors.Is(err, target) != (target == tc.expected) {
+				t.Errorf("test %d: errors.Is(%v) should be %v, err:%v", i+1, target, target == tc.expected, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package agollo
+
+import "reflect"
+
+// Get get value of key in namespace from default agollo and convert it into T,
+// T could be any type supported by the getters, e.g. int64, time.Duration,
+// []string or map[string]int
+func Get[T any](namespace, key string) (T, bool) {
+	return GetFrom[T](defaultAgollo.Client, namespace, key)
+}
+
+// GetFrom is like Get, but read from the given client
+func GetFrom[T any](c *Client, namespace, key string) (T, bool) {
+	var zero T
+	if c == nil {
+		return zero, false
+	}
+	val, ok := c.getValue(namespace, key)
+	if !ok {
+		return zero, false
+	}
+	if v, ok := val.(T); ok {
+		return v, true
+	}
+	v, err := convertTo(val, reflect.TypeOf(&zero).Elem())
+	if err != nil {
+		return zero, false
+	}
+	return v.Interface().(T), true
+}
@@ -0,0 +1,37 @@
+package agollo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ZhengHe-MD/agollo/v4/internal/mockserver"
+)
+
+func TestGet(t *testing.T) {
+	mockserver.Set(defaultNamespace, "gk_timeout", "3s")
+	mockserver.Set(defaultNamespace, "gk_ports", "80,443")
+	mockserver.Set(defaultNamespace, "gk_name", "agollo")
+
+	if err := StartWithConf(defaultConf); err != nil {
+		t.Error(err)
+	}
+	_ = defaultAgollo.Client.preload(context.Background())
+
+	if v, ok := Get[time.Duration](defaultNamespace, "gk_timeout"); !ok || v != time.Second*3 {
+		t.Errorf("Get duration got:%v %v", v, ok)
+	}
+	if v, ok := Get[[]uint16](defaultNamespace, "gk_ports"); !ok || !reflect.DeepEqual(v, []uint16{80, 443}) {
+		t.Errorf("Get ports got:%v %v", v, ok)
+	}
+	if v, ok := GetFrom[string](defaultAgollo.Client, defaultNamespace, "gk_name"); !ok || v != "agollo" {
+		t.Errorf("GetFrom string got:%v %v", v, ok)
+	}
+	if _, ok := Get[int](defaultNamespace, "gk_name"); ok {
+		t.Errorf("Get int should fail on bad value")
+	}
+	if _, ok := Get[int](nonExistNamespace, "gk_name"); ok {
+		t.Errorf("Get should fail on missing namespace")
+	}
+}
@@ -1,11 +1,8 @@
 module github.com/ZhengHe-MD/agollo/v4
 
-go 1.16
+go 1.18
 
 require (
-	github.com/ZhengHe-MD/agollo v2.1.0+incompatible
-	github.com/spf13/viper v1.8.1
-	github.com/stretchr/testify v1.7.0
-	github.com/tevid/gohamcrest v1.1.1 // indirect
+	github.com/mitchellh/mapstructure v1.4.1
 	gopkg.in/yaml.v2 v2.4.0
 )
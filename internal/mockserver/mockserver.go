@@ -2,8 +2,12 @@ package mockserver
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -28,12 +32,39 @@ type mockServer struct {
 	lock          sync.Mutex
 	notifications map[string]int
 	config        map[string]map[string]string
+	secret        string
+}
+
+// authorized check apollo access key signature if secret is set
+func (s *mockServer) authorized(req *http.Request, appID string) bool {
+	s.lock.Lock()
+	secret := s.secret
+	s.lock.Unlock()
+
+	if secret == "" {
+		return true
+	}
+
+	timestamp := req.Header.Get("Timestamp")
+	pathWithQuery := req.URL.EscapedPath()
+	if req.URL.RawQuery != "" {
+		pathWithQuery += "?" + req.URL.RawQuery
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + pathWithQuery))
+	expected := "Apollo " + appID + ":" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return timestamp != "" && hmac.Equal([]byte(req.Header.Get("Authorization")), []byte(expected))
 }
 
 func (s *mockServer) NotificationHandler(rw http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	if !s.authorized(req, req.FormValue("appId")) {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	req.ParseForm()
 	var notifications []notification
 	if err := json.Unmarshal([]byte(req.FormValue("notifications")), &notifications); err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -62,8 +93,16 @@ func (s *mockServer) ConfigHandler(rw http.ResponseWriter, req *http.Request) {
 	req.ParseForm()
 
 	strs := strings.Split(req.RequestURI, "/")
-	var namespace, releaseKey = strings.Split(strs[4], "?")[0], req.FormValue("releaseKey")
-	config := s.Get(namespace)
+	if !s.authorized(req, strs[2]) {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var namespace = strings.Split(strs[4], "?")[0]
+	config, releaseKey := s.Get(namespace), s.releaseKey(namespace)
+	if req.FormValue("releaseKey") == releaseKey {
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
 
 	var result = result{NamespaceName: namespace, Configurations: config, ReleaseKey: releaseKey}
 	bts, err := json.Marshal(&result)
@@ -74,6 +113,36 @@ func (s *mockServer) ConfigHandler(rw http.ResponseWriter, req *http.Request) {
 	rw.Write(bts)
 }
 
+type service struct {
+	AppName     string `json:"appName"`
+	InstanceID  string `json:"instanceId"`
+	HomepageURL string `json:"homepageUrl"`
+}
+
+// ServicesHandler act as meta server, return mock server itself as the only
+// config service
+func (s *mockServer) ServicesHandler(rw http.ResponseWriter, req *http.Request) {
+	services := []service{{
+		AppName:     "APOLLO-CONFIGSERVICE",
+		InstanceID:  "localhost:apollo-configservice:8080",
+		HomepageURL: "http://localhost" + s.server.Addr + "/",
+	}}
+	bts, err := json.Marshal(&services)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rw.Write(bts)
+}
+
+// releaseKey of namespace changes whenever it's modified
+func (s *mockServer) releaseKey(namespace string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return strconv.Itoa(s.notifications[namespace])
+}
+
 var server *mockServer
 
 func (s *mockServer) Set(namespace, key, value string) {
@@ -112,6 +181,18 @@ func (s *mockServer) Delete(namespace, key string) {
 	s.notifications[namespace] = notificationID
 }
 
+func (s *mockServer) SetSecret(secret string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.secret = secret
+}
+
+// SetSecret enable access key signature check, empty secret disables it
+func SetSecret(secret string) {
+	server.SetSecret(secret)
+}
+
 // Set namespace's key value
 func Set(namespace, key, value string) {
 	server.Set(namespace, key, value)
@@ -136,6 +217,7 @@ func initServer() {
 	mux := http.NewServeMux()
 	mux.Handle("/notifications/", http.HandlerFunc(server.NotificationHandler))
 	mux.Handle("/configs/", http.HandlerFunc(server.ConfigHandler))
+	mux.Handle("/services/config", http.HandlerFunc(server.ServicesHandler))
 	server.server.Handler = mux
 	server.server.Addr = ":8080"
 }
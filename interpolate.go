@@ -0,0 +1,208 @@
+package agollo
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	placeholderPrefix  = "${"
+	placeholderSuffix  = "}"
+	namespaceSeparator = "::"
+	defaultSeparator   = ":"
+)
+
+// lookupFunc return raw value of key in namespace
+type lookupFunc func(namespace, key string) (interface{}, bool)
+
+// interpolator resolve placeholders like ${key}, ${key:default} and
+// ${namespace::key} in string values, defaults can be placeholders too, e.g.
+// ${key:${fallback}}, keys not found in apollo are looked
+// up in environment variables, unresolvable placeholders are kept as is
+type interpolator struct {
+	lookup lookupFunc
+	env    func(key string) (string, bool)
+	stack  []string
+}
+
+func newInterpolator(lookup lookupFunc) *interpolator {
+	return &interpolator{lookup: lookup, env: os.LookupEnv}
+}
+
+// resolve return interpolated value of key in namespace
+func (i *interpolator) resolve(namespace, key string) (interface{}, bool, error) {
+	ref := namespace + namespaceSeparator + key
+	for idx, visiting := range i.stack {
+		if visiting == ref {
+			path := append(append([]string(nil), i.stack[idx:]...), ref)
+			return nil, false, fmt.Errorf("%w: %s", ErrCircularReference, strings.Join(path, " -> "))
+		}
+	}
+
+	val, ok := i.lookup(namespace, key)
+	if !ok {
+		return nil, false, nil
+	}
+	s, isStr := val.(string)
+	if !isStr || !strings.Contains(s, placeholderPrefix) {
+		return val, true, nil
+	}
+
+	i.stack = append(i.stack, ref)
+	defer func() { i.stack = i.stack[:len(i.stack)-1] }()
+
+	ret, err := i.expand(namespace, s)
+	if err != nil {
+		return nil, false, err
+	}
+	return ret, true, nil
+}
+
+// expand replace all placeholders in s, keys without namespace are relative
+// to namespace
+func (i *interpolator) expand(namespace, s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, placeholderPrefix)
+		if start < 0 {
+			break
+		}
+		end := closingSuffix(s, start+len(placeholderPrefix))
+		if end < 0 {
+			break
+		}
+
+		b.WriteString(s[:start])
+		val, err := i.placeholder(namespace, s[start+len(placeholderPrefix):end], s[start:end+1])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(val)
+		s = s[end+1:]
+	}
+	b.WriteString(s)
+	return b.String(), nil
+}
+
+// closingSuffix return index of the suffix closing the placeholder whose
+// body starts at from, suffixes of nested placeholders are skipped, it
+// return -1 if there is none
+func closingSuffix(s string, from int) int {
+	depth := 1
+	for idx := from; idx < len(s); idx++ {
+		switch {
+		case strings.HasPrefix(s[idx:], placeholderPrefix):
+			depth++
+			idx += len(placeholderPrefix) - 1
+		case strings.HasPrefix(s[idx:], placeholderSuffix):
+			depth--
+			if depth == 0 {
+				return idx
+			}
+		}
+	}
+	return -1
+}
+
+func (i *interpolator) placeholder(namespace, expr, literal string) (string, error) {
+	// separators are searched before nested placeholders of default
+	head := expr
+	if idx := strings.Index(head, placeholderPrefix); idx >= 0 {
+		head = head[:idx]
+	}
+	keyNamespace := namespace
+	if idx := strings.Index(head, namespaceSeparator); idx >= 0 {
+		keyNamespace, expr, head = expr[:idx], expr[idx+len(namespaceSeparator):], head[idx+len(namespaceSeparator):]
+	}
+	key, def, hasDef := expr, "", false
+	if idx := strings.Index(head, defaultSeparator); idx >= 0 {
+		key, def, hasDef = expr[:idx], expr[idx+len(defaultSeparator):], true
+	}
+	key = strings.TrimSpace(key)
+
+	val, ok, err := i.resolve(keyNamespace, key)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return toString(val)
+	}
+	if v, ok := i.env(key); ok {
+		return v, nil
+	}
+	if hasDef {
+		return i.expand(namespace, def)
+	}
+	return literal, nil
+}
+
+// rawLookup return a lookupFunc over caches and chains, if changed is not nil,
+// keys in it are looked up with their old values
+func (c *Client) rawLookup(changed *ChangeEvent) lookupFunc {
+	var lookup lookupFunc
+	lookup = func(namespace, key string) (interface{}, bool) {
+		if namespaces, ok := c.chains.get(namespace); ok {
+			for _, ns := range namespaces {
+				if val, ok := lookup(ns, key); ok {
+					return val, true
+				}
+			}
+			return nil, false
+		}
+		if changed != nil && namespace == changed.Namespace {
+			if change, ok := changed.Changes[key]; ok {
+				return change.OldValue, change.ChangeType != ADD
+			}
+		}
+		return c.mustGetCache(namespace).get(key)
+	}
+	return lookup
+}
+
+// dependentChangeEvents find keys whose interpolated values are changed by
+// change, dependent keys of the same namespace are added to change, others
+// are returned as events grouped by namespace
+func (c *Client) dependentChangeEvents(change *ChangeEvent) []*ChangeEvent {
+	if !c.conf.Interpolate {
+		return nil
+	}
+
+	before, after := c.rawLookup(change), c.rawLookup(nil)
+
+	var events []*ChangeEvent
+	for _, namespace := range c.caches.namespaces() {
+		event := c.derivedChangeEvent(change, namespace)
+		if namespace == change.Namespace {
+			event = change
+		}
+
+		for key, val := range c.mustGetCache(namespace).dump() {
+			if s, ok := val.(string); !ok || !strings.Contains(s, placeholderPrefix) {
+				continue
+			}
+			if _, ok := event.Changes[key]; ok {
+				continue
+			}
+
+			old, _, err := newInterpolator(before).resolve(namespace, key)
+			if err != nil {
+				continue
+			}
+			cur, _, err := newInterpolator(after).resolve(namespace, key)
+			if err != nil {
+				continue
+			}
+			if !reflect.DeepEqual(old, cur) {
+				event.Changes[key] = makeModifyChange(key, old, cur)
+			}
+		}
+
+		if event != change && len(event.Changes) > 0 {
+			event.Snapshot = newSnapshot(namespace, event.NewReleaseKey, c.getRawValues(namespace))
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,75 @@
+package agollo
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	os.Setenv("AGOLLO_TEST_ENV", "from-env")
+	defer os.Unsetenv("AGOLLO_TEST_ENV")
+
+	c := NewClient(&Conf{AppID: "InterpolateApp", CacheDir: "/tmp/agollo", Interpolate: true})
+	c.handleResult(&result{NamespaceName: "db", Configurations: map[string]interface{}{"host": "localhost"}})
+	c.handleResult(&result{NamespaceName: defaultNamespace, Configurations: map[string]interface{}{
+		"port":    "3306",
+		"url":     "jdbc://${db::host}:${port}/${name:test}",
+		"env":     "${AGOLLO_TEST_ENV}",
+		"unknown": "${nothing}",
+		"a":       "${b}",
+		"b":       "${a}",
+		"timeout": "${seconds:3}s",
+		"nested":  "${missing:${db::host}}/${missing:${other:${port}}}",
+		"partial": "${missing:${nothing}}",
+	}})
+
+	var tcs = []struct {
+		key      string
+		expected string
+	}{
+		{"url", "jdbc://localhost:3306/test"},
+		{"env", "from-env"},
+		{"unknown", "${nothing}"},
+		{"timeout", "3s"},
+		{"nested", "localhost/3306"},
+		{"partial", "${nothing}"},
+	}
+	for _, tc := range tcs {
+		if v, err := c.GetStringE(tc.key); err != nil || v != tc.expected {
+			t.Errorf("key %s: expected:%s got:%s %v", tc.key, tc.expected, v, err)
+		}
+	}
+
+	if _, err := c.GetStringE("a"); !errors.Is(err, ErrCircularReference) {
+		t.Errorf("expected circular reference, got:%v", err)
+	}
+	if v, ok := c.GetDuration("timeout"); !ok || v.Seconds() != 3 {
+		t.Errorf("interpolated value should be converted, got:%v %v", v, ok)
+	}
+
+	change := c.handleResult(&result{NamespaceName: "db", Configurations: map[string]interface{}{"host": "remote"}})
+	events := c.dependentChangeEvents(change)
+	if len(events) != 1 || events[0].Namespace != defaultNamespace {
+		t.Fatalf("expected one dependent event, got:%v", events)
+	}
+	expected := map[string]*Change{
+		"url":    makeModifyChange("url", "jdbc://localhost:3306/test", "jdbc://remote:3306/test"),
+		"nested": makeModifyChange("nested", "localhost/3306", "remote/3306"),
+	}
+	if !reflect.DeepEqual(events[0].Changes, expected) {
+		t.Errorf("unexpected changes:%v", events[0].Changes)
+	}
+
+	change = c.handleResult(&result{NamespaceName: defaultNamespace, Configurations: map[string]interface{}{
+		"port": "3307",
+		"url":  "jdbc://${db::host}:${port}/${name:test}",
+	}})
+	if events := c.dependentChangeEvents(change); len(events) != 0 {
+		t.Errorf("dependent keys of the same namespace should be added to change, got:%v", events)
+	}
+	if ch, ok := change.Changes["url"]; !ok || ch.NewValue != "jdbc://remote:3307/test" {
+		t.Errorf("url should be changed, got:%v", change.Changes)
+	}
+}
@@ -1,5 +1,258 @@
 package agollo
 
+import (
+	"context"
+	"reflect"
+	"runtime/debug"
+	"sync"
+)
+
 type ChangeEventObserver interface {
 	HandleChangeEvent(event *ChangeEvent)
 }
+
+// OverflowPolicy decide what to do when queue of an observer is full
+type OverflowPolicy int
+
+const (
+	// OverflowCoalesce merge event into the last queued one of the same
+	// namespace, which is dropped if changes cancel out, the oldest event is
+	// dropped if there is none. It's the default
+	OverflowCoalesce OverflowPolicy = iota
+	// OverflowDropOldest drop the oldest queued event
+	OverflowDropOldest
+	// OverflowBlock wait until the observer catches up, it stalls delivery
+	// to other observers, and then the poller
+	OverflowBlock
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "BLOCK"
+	case OverflowDropOldest:
+		return "DROP_OLDEST"
+	case OverflowCoalesce:
+		return "COALESCE"
+	}
+
+	return "UNKNOW"
+}
+
+// ObserverStats is a snapshot of the delivery queue of an observer
+type ObserverStats struct {
+	Observer   ChangeEventObserver
+	QueueDepth int
+	Delivered  uint64
+	Dropped    uint64
+	Coalesced  uint64
+	Panics     uint64
+}
+
+// observerQueue deliver events to one observer in its own goroutine, so
+// slow or panicking observers don't affect others
+type observerQueue struct {
+	observer ChangeEventObserver
+	size     int
+	policy   OverflowPolicy
+	// pending is called with the change of queued events, see dispatcher.wait
+	pending func(delta int)
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	events []*ChangeEvent
+	closed bool
+	done   chan struct{}
+	stats  ObserverStats
+}
+
+func newObserverQueue(observer ChangeEventObserver, size int, policy OverflowPolicy, pending func(delta int)) *observerQueue {
+	if size <= 0 {
+		size = defaultObserverQueueSize
+	}
+	q := &observerQueue{
+		observer: observer,
+		size:     size,
+		policy:   policy,
+		pending:  pending,
+	}
+	q.cond = sync.NewCond(&q.lock)
+	return q
+}
+
+// push queue event, start the delivery goroutine if needed
+func (q *observerQueue) push(event *ChangeEvent) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for q.policy == OverflowBlock && len(q.events) >= q.size && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return
+	}
+
+	if q.done == nil {
+		q.done = make(chan struct{})
+		go q.run(q.done)
+	}
+
+	if len(q.events) >= q.size && q.policy == OverflowCoalesce {
+		for i := len(q.events) - 1; i >= 0; i-- {
+			if q.events[i].Namespace == event.Namespace {
+				coalesce(q.events[i], event)
+				q.stats.Coalesced++
+				// changes cancel out, e.g. an ADD followed by a DELETE
+				if len(q.events[i].Changes) == 0 && len(event.Changes) > 0 {
+					q.events = append(q.events[:i:i], q.events[i+1:]...)
+					q.pending(-1)
+					q.cond.Broadcast()
+				}
+				return
+			}
+		}
+	}
+
+	if len(q.events) >= q.size {
+		q.events = q.events[1:]
+		q.stats.Dropped++
+		q.pending(-1)
+	}
+	q.events = append(q.events, copyChangeEvent(event))
+	q.pending(1)
+	q.cond.Broadcast()
+}
+
+func (q *observerQueue) run(done chan struct{}) {
+	defer close(done)
+
+	for {
+		q.lock.Lock()
+		for len(q.events) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.events) == 0 {
+			q.lock.Unlock()
+			return
+		}
+		event := q.events[0]
+		q.events = q.events[1:]
+		q.cond.Broadcast()
+		q.lock.Unlock()
+
+		panicked := q.handle(event)
+
+		q.lock.Lock()
+		q.stats.Delivered++
+		if panicked {
+			q.stats.Panics++
+		}
+		q.lock.Unlock()
+		q.pending(-1)
+	}
+}
+
+func (q *observerQueue) handle(event *ChangeEvent) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			defaultLogger.Printf("module:agollo method:observerQueue.handle observer:%T namespace:%s panic:%v\n%s", q.observer, event.Namespace, r, debug.Stack())
+		}
+	}()
+
+	q.observer.HandleChangeEvent(event)
+	return false
+}
+
+// close stop accepting events, queued events are still delivered if drain is
+// true, or dropped otherwise
+func (q *observerQueue) close(drain bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.closed = true
+	if !drain && len(q.events) > 0 {
+		q.stats.Dropped += uint64(len(q.events))
+		q.pending(-len(q.events))
+		q.events = nil
+	}
+	q.cond.Broadcast()
+}
+
+// wait block until the delivery goroutine exits after close, or ctx is done
+func (q *observerQueue) wait(ctx context.Context) error {
+	q.lock.Lock()
+	done := q.done
+	q.lock.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *observerQueue) getStats() ObserverStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	stats := q.stats
+	stats.Observer = q.observer
+	stats.QueueDepth = len(q.events)
+	return stats
+}
+
+// copyChangeEvent copy event so it can be coalesced without affecting other
+// observers
+func copyChangeEvent(event *ChangeEvent) *ChangeEvent {
+	ret := *event
+	ret.Changes = make(map[string]*Change, len(event.Changes))
+	for k, v := range event.Changes {
+		change := *v
+		ret.Changes[k] = &change
+	}
+	return &ret
+}
+
+// coalesce merge changes of next into queued, as if they happened at once
+func coalesce(queued, next *ChangeEvent) {
+	queued.NewReleaseKey = next.NewReleaseKey
+	queued.Timestamp = next.Timestamp
+	queued.Source = next.Source
+	queued.Snapshot = next.Snapshot
+
+	for k, v := range next.Changes {
+		prev, ok := queued.Changes[k]
+		if !ok {
+			change := *v
+			queued.Changes[k] = &change
+			continue
+		}
+
+		var merged *Change
+		switch {
+		case prev.ChangeType == ADD && v.ChangeType == DELETE:
+		case prev.ChangeType == ADD:
+			merged = makeAddChange(k, v.NewValue)
+		case v.ChangeType == DELETE:
+			merged = makeDeleteChange(k, prev.OldValue)
+		case prev.ChangeType == DELETE || !reflect.DeepEqual(prev.OldValue, v.NewValue):
+			merged = makeModifyChange(k, prev.OldValue, v.NewValue)
+		}
+		// a DELETE followed by an ADD of the same value is no change either
+		if merged != nil && merged.ChangeType == MODIFY && reflect.DeepEqual(merged.OldValue, merged.NewValue) {
+			merged = nil
+		}
+
+		if merged == nil {
+			delete(queued.Changes, k)
+		} else {
+			queued.Changes[k] = merged
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package agollo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	lock    sync.Mutex
+	events  []*ChangeEvent
+	entered chan struct{}
+	blocker chan struct{}
+}
+
+func (o *recordingObserver) HandleChangeEvent(event *ChangeEvent) {
+	if o.entered != nil {
+		o.entered <- struct{}{}
+	}
+	if o.blocker != nil {
+		<-o.blocker
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.events = append(o.events, event)
+}
+
+type panicObserver struct {
+	count int32
+}
+
+func (o *panicObserver) HandleChangeEvent(event *ChangeEvent) {
+	atomic.AddInt32(&o.count, 1)
+	panic("boom")
+}
+
+func modifyEvent(namespace, key string, old, new interface{}) *ChangeEvent {
+	return &ChangeEvent{
+		Namespace:     namespace,
+		Changes:       map[string]*Change{key: makeModifyChange(key, old, new)},
+		OldReleaseKey: fmt.Sprint(old),
+		NewReleaseKey: fmt.Sprint(new),
+	}
+}
+
+func TestObserverIsolation(t *testing.T) {
+	c := NewClient(&Conf{AppID: "ObserverApp", CacheDir: "/tmp/agollo", ObserverQueueSize: 2, ObserverOverflow: OverflowDropOldest})
+	slow := &recordingObserver{entered: make(chan struct{}, 5), blocker: make(chan struct{})}
+	fast := &recordingObserver{entered: make(chan struct{}, 5)}
+	bad := &panicObserver{}
+	c.registerObserver(slow)
+	c.registerObserver(fast)
+	c.registerObserver(bad)
+	c.StartWatchUpdate()
+
+	// fast observer is not stalled by the blocked one
+	for i := 0; i < 5; i++ {
+		c.deliveryChangeEvent(modifyEvent(defaultNamespace, "k", i, i+1))
+		select {
+		case <-fast.entered:
+		case <-time.After(time.Second):
+			t.Fatalf("fast observer should get event %d", i)
+		}
+		if i == 0 {
+			<-slow.entered
+		}
+	}
+
+	close(slow.blocker)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.WaitDispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := c.GetObserverStats()
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 stats, got:%d", len(stats))
+	}
+	// slow observer holds one event in hand, and keeps the latest 2 queued
+	if s := stats[0]; s.Observer != slow || s.Delivered != 3 || s.Dropped != 2 || s.QueueDepth != 0 {
+		t.Errorf("unexpected stats of slow observer:%+v", s)
+	}
+	if s := stats[1]; s.Delivered != 5 || s.Dropped != 0 {
+		t.Errorf("unexpected stats of fast observer:%+v", s)
+	}
+	if s := stats[2]; s.Panics == 0 || s.Panics != s.Delivered || s.Delivered+s.Dropped != 5 || int32(s.Panics) != atomic.LoadInt32(&bad.count) {
+		t.Errorf("panics should be recovered, got:%+v", s)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObserverCoalesce(t *testing.T) {
+	// coalesce is the default
+	c := NewClient(&Conf{AppID: "ObserverApp", CacheDir: "/tmp/agollo", ObserverQueueSize: 2})
+	ob := &recordingObserver{entered: make(chan struct{}, 3), blocker: make(chan struct{})}
+	c.registerObserver(ob)
+	c.StartWatchUpdate()
+
+	// the first event is taken by observer, the next two fill the queue,
+	// the rest are coalesced
+	c.deliveryChangeEvent(modifyEvent(defaultNamespace, "k", 0, 1))
+	<-ob.entered
+	c.deliveryChangeEvent(modifyEvent(defaultNamespace, "k", 1, 2))
+	c.deliveryChangeEvent(modifyEvent("other", "k", 0, 1))
+	c.deliveryChangeEvent(modifyEvent(defaultNamespace, "k", 2, 3))
+	c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace, Changes: map[string]*Change{"n": makeAddChange("n", "v")}})
+	c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace, Changes: map[string]*Change{"n": makeDeleteChange("n", "v")}})
+
+	close(ob.blocker)
+	if err := c.WaitDispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ob.events) != 3 {
+		t.Fatalf("expected 3 events, got:%d", len(ob.events))
+	}
+	expected := map[string]*Change{"k": makeModifyChange("k", 1, 3)}
+	if !reflect.DeepEqual(ob.events[1].Changes, expected) {
+		t.Errorf("unexpected coalesced changes:%v", ob.events[1].Changes)
+	}
+	if ob.events[1].OldReleaseKey != "1" || ob.events[1].NewReleaseKey != "" {
+		t.Errorf("release keys should span coalesced events, got:%s %s", ob.events[1].OldReleaseKey, ob.events[1].NewReleaseKey)
+	}
+	if s := c.GetObserverStats()[0]; s.Coalesced != 3 {
+		t.Errorf("expected 3 coalesced events, got:%+v", s)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObserverCoalesceCancelled(t *testing.T) {
+	c := NewClient(&Conf{AppID: "ObserverApp", CacheDir: "/tmp/agollo", ObserverQueueSize: 1})
+	ob := &recordingObserver{entered: make(chan struct{}, 2), blocker: make(chan struct{})}
+	c.registerObserver(ob)
+	c.StartWatchUpdate()
+
+	c.deliveryChangeEvent(modifyEvent(defaultNamespace, "k", 0, 1))
+	<-ob.entered
+	c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace, Changes: map[string]*Change{"n": makeAddChange("n", "v")}})
+	c.deliveryChangeEvent(&ChangeEvent{Namespace: defaultNamespace, Changes: map[string]*Change{"n": makeDeleteChange("n", "v")}})
+	if s := c.GetObserverStats()[0]; s.QueueDepth != 0 {
+		t.Errorf("event without changes should be dropped, got:%+v", s)
+	}
+
+	close(ob.blocker)
+	if err := c.WaitDispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(ob.events) != 1 {
+		t.Errorf("expected 1 event, got:%d", len(ob.events))
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObserverBlock(t *testing.T) {
+	c := NewClient(&Conf{AppID: "ObserverApp", CacheDir: "/tmp/agollo", ObserverQueueSize: 1, ObserverOverflow: OverflowBlock})
+	ob := &recordingObserver{blocker: make(chan struct{})}
+	c.registerObserver(ob)
+	c.StartWatchUpdate()
+
+	for i := 0; i < 4; i++ {
+		c.deliveryChangeEvent(modifyEvent(defaultNamespace, "k", i, i+1))
+	}
+	close(ob.blocker)
+	if err := c.WaitDispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.GetObserverStats()[0]; s.Delivered != 4 || s.Dropped != 0 {
+		t.Errorf("no event should be dropped, got:%+v", s)
+	}
+
+	// recalled observer stops receiving events
+	c.recallObserver(ob)
+	c.deliveryChangeEvent(modifyEvent(defaultNamespace, "k", 4, 5))
+	if err := c.WaitDispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(ob.events) != 4 {
+		t.Errorf("expected 4 events, got:%d", len(ob.events))
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
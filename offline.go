@@ -0,0 +1,135 @@
+package agollo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ZhengHe-MD/agollo/v4/parse"
+	"github.com/ZhengHe-MD/agollo/v4/parse/properties"
+)
+
+// isOffline report whether client serves local files only, see Conf.Offline
+func (c *Client) isOffline() bool {
+	c.offlineLock.Lock()
+	defer c.offlineLock.Unlock()
+
+	return c.offline
+}
+
+// subscribeOffline remember namespaces subscribed while offline, they are
+// subscribed to apollo by GoOnline. It return false if client is online
+func (c *Client) subscribeOffline(namespaces ...string) bool {
+	c.offlineLock.Lock()
+	defer c.offlineLock.Unlock()
+
+	if !c.offline {
+		return false
+	}
+	c.offlineNamespaces = append(c.offlineNamespaces, namespaces...)
+	return true
+}
+
+// loadOffline load namespaces from conf.OfflineDir, or store if it's empty
+func (c *Client) loadOffline(namespaces ...string) error {
+	var err error
+	for _, namespace := range namespaces {
+		if e := c.loadOfflineNamespace(namespace); e != nil {
+			defaultLogger.Printf("module:agollo method:Client.loadOffline namespace:%v, err:%v", namespace, e)
+			err = &NamespaceError{Namespace: namespace, Err: e}
+		}
+	}
+	return err
+}
+
+func (c *Client) loadOfflineNamespace(namespace string) error {
+	if c.conf.OfflineDir == "" {
+		return c.loadLocal(namespace)
+	}
+
+	entry, err := loadOfflineFile(c.conf.OfflineDir, namespace, c.conf)
+	if err != nil {
+		return err
+	}
+	c.resetLocal(entry, SourceLocal)
+	return nil
+}
+
+// loadOfflineFile read namespace from dir, which can be a copy of cache dir,
+// or contains {namespace} for file namespaces, e.g. app.yaml, and
+// {namespace}.properties for others
+func loadOfflineFile(dir, namespace string, conf *Conf) (*CacheEntry, error) {
+	if err := checkNamespaceName(namespace); err != nil {
+		return nil, err
+	}
+	entry, err := newDefaultCacheStore(dir, conf).Load(namespace)
+	if !errors.Is(err, ErrCacheNotFound) {
+		return entry, err
+	}
+
+	if parse.GetNamespaceType(namespace) != "" {
+		bts, err := ioutil.ReadFile(filepath.Join(dir, namespace))
+		if err == nil {
+			return &CacheEntry{Namespace: namespace, Configurations: map[string]interface{}{dumpContentKey: string(bts)}}, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	name := filepath.Join(dir, namespace+".properties")
+	bts, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrCacheNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	kv, err := properties.Load(string(bts))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	configurations := make(map[string]interface{}, len(kv))
+	for k, v := range kv {
+		configurations[k] = v
+	}
+	return &CacheEntry{Namespace: namespace, Configurations: configurations}, nil
+}
+
+// GoOnline switch client started with Conf.Offline to apollo, values loaded
+// locally are replaced, and changes are delivered to observers. Namespaces
+// failed to fetch are retried by the long poller, whose error is returned.
+// It's a no-op if client is online already
+func (c *Client) GoOnline(ctx context.Context) error {
+	c.offlineLock.Lock()
+	if !c.offline {
+		c.offlineLock.Unlock()
+		return nil
+	}
+	c.offline = false
+	subscribed := c.offlineNamespaces
+	c.offlineNamespaces = nil
+	c.offlineLock.Unlock()
+
+	c.watchServers(ctx)
+
+	var err error
+	for _, namespace := range append(append([]string(nil), c.conf.NameSpaceNames...), subscribed...) {
+		if e := c.handleNamespaceUpdate(ctx, namespace); e != nil {
+			defaultLogger.Printf("module:agollo method:Client.GoOnline namespace:%v, err:%v", namespace, e)
+			err = e
+		}
+	}
+	if len(subscribed) > 0 {
+		if e := c.longPoller.addNamespaces(ctx, subscribed...); e != nil {
+			err = e
+		}
+	}
+
+	c.longPoller.start()
+	return err
+}
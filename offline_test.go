@@ -0,0 +1,136 @@
+package agollo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOffline(t *testing.T) {
+	var requests int32
+	serv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if !strings.HasPrefix(req.URL.Path, "/configs/") {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		namespace := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		rw.Write([]byte(`{"namespaceName":"` + namespace + `","configurations":{"key":"remote"},"releaseKey":"1"}`))
+	}))
+	defer serv.Close()
+
+	dir, err := ioutil.TempDir("", "agollo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"application.properties": "key=local\n",
+		"extra.properties":       "key=extra\n",
+		"app.yaml":               "db:\n  host: localhost\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewClient(&Conf{
+		AppID:          "OfflineApp",
+		Cluster:        "default",
+		NameSpaceNames: []string{defaultNamespace, "app.yaml"},
+		IP:             serv.URL,
+		CacheStore:     NewMemoryCacheStore(),
+		Offline:        true,
+		OfflineDir:     dir,
+	})
+	updates := c.WatchUpdate()
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	select {
+	case ce := <-updates:
+		if ce.Source != SourceLocal {
+			t.Errorf("unexpected source:%v", ce.Source)
+		}
+	default:
+		t.Errorf("change event expected after loading local files")
+	}
+
+	if v, _ := c.GetString("key"); v != "local" {
+		t.Errorf("key expected:local got:%v", v)
+	}
+	if v, _ := c.GetStringWithNamespace("app.yaml", "db.host"); v != "localhost" {
+		t.Errorf("db.host expected:localhost got:%v", v)
+	}
+	if status, _ := c.GetNamespaceStatus(defaultNamespace); status.Source != SourceLocal {
+		t.Errorf("unexpected status:%+v", status)
+	}
+	if err := c.SubscribeToNamespaces("extra"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetStringWithNamespace("extra", "key"); v != "extra" {
+		t.Errorf("extra key expected:extra got:%v", v)
+	}
+	if err := c.SubscribeToNamespaces("missing"); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("missing namespace should fail with ErrCacheNotFound, got:%v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("offline client should not request apollo, got:%d", n)
+	}
+
+	changed := make(chan interface{}, 1)
+	c.WatchKey(defaultNamespace, "key", func(old, new interface{}) { changed <- new })
+	c.StartWatchUpdate()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.GoOnline(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.GetStringWithNamespace("extra", "key"); v != "remote" {
+		t.Errorf("extra key expected:remote got:%v", v)
+	}
+	if status, _ := c.GetNamespaceStatus(defaultNamespace); status.Source != SourceRemote {
+		t.Errorf("unexpected status:%+v", status)
+	}
+	select {
+	case v := <-changed:
+		if v != "remote" {
+			t.Errorf("change expected:remote got:%v", v)
+		}
+	case <-ctx.Done():
+		t.Errorf("change should be delivered after GoOnline")
+	}
+
+	if err := c.GoOnline(ctx); err != nil {
+		t.Errorf("GoOnline should be a no-op once online, got:%v", err)
+	}
+}
+
+func TestOfflineCacheStore(t *testing.T) {
+	store := NewMemoryCacheStore()
+	store.Save(&CacheEntry{Namespace: defaultNamespace, ReleaseKey: "1", Configurations: map[string]interface{}{"key": "cached"}})
+
+	c := NewClient(&Conf{AppID: "OfflineApp", NameSpaceNames: []string{defaultNamespace}, CacheStore: store, Offline: true})
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	if v, _ := c.GetString("key"); v != "cached" {
+		t.Errorf("key expected:cached got:%v", v)
+	}
+	if status, _ := c.GetNamespaceStatus(defaultNamespace); status.Source != SourceCache {
+		t.Errorf("unexpected status:%+v", status)
+	}
+}
@@ -0,0 +1,75 @@
+// Package flatten turn nested config into flat keys, while keeping original
+// key case
+package flatten
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Flatten turn a nested map into flat keys, every node is reachable by its
+// path, e.g. for
+//
+//	db:
+//	  hosts:
+//	    - name: a
+//
+// keys "db", "db.hosts", "db.hosts[0]" and "db.hosts[0].name" are returned,
+// maps are returned as map[string]interface{}, slices as []interface{}
+func Flatten(root interface{}) (map[string]interface{}, error) {
+	if root == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	m, ok := Normalize(root).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("root of content should be a map, got %T", root)
+	}
+
+	ret := make(map[string]interface{})
+	for k, v := range m {
+		flatten(ret, k, v)
+	}
+	return ret, nil
+}
+
+func flatten(ret map[string]interface{}, path string, val interface{}) {
+	ret[path] = val
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for k, sub := range v {
+			flatten(ret, path+"."+k, sub)
+		}
+	case []interface{}:
+		for i, sub := range v {
+			flatten(ret, path+"["+strconv.Itoa(i)+"]", sub)
+		}
+	}
+}
+
+// Normalize convert map[interface{}]interface{} produced by yaml into
+// map[string]interface{}, recursively
+func Normalize(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, sub := range v {
+			m[fmt.Sprint(k)] = Normalize(sub)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, sub := range v {
+			m[k] = Normalize(sub)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, sub := range v {
+			s[i] = Normalize(sub)
+		}
+		return s
+	}
+	return val
+}
@@ -0,0 +1,46 @@
+package flatten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	root := map[interface{}]interface{}{
+		"maxConns": 10,
+		"db": map[interface{}]interface{}{
+			"Host": "localhost",
+			"hosts": []interface{}{
+				map[interface{}]interface{}{"name": "a"},
+				"b",
+			},
+		},
+	}
+
+	ret, err := Flatten(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := []interface{}{map[string]interface{}{"name": "a"}, "b"}
+	expected := map[string]interface{}{
+		"maxConns":         10,
+		"db":               map[string]interface{}{"Host": "localhost", "hosts": hosts},
+		"db.Host":          "localhost",
+		"db.hosts":         hosts,
+		"db.hosts[0]":      map[string]interface{}{"name": "a"},
+		"db.hosts[0].name": "a",
+		"db.hosts[1]":      "b",
+	}
+	if !reflect.DeepEqual(ret, expected) {
+		t.Errorf("expected:%v got:%v", expected, ret)
+	}
+
+	if ret, err := Flatten(nil); err != nil || len(ret) != 0 {
+		t.Errorf("nil root should be flattened to empty map, got:%v %v", ret, err)
+	}
+
+	if _, err := Flatten([]interface{}{1}); err == nil {
+		t.Errorf("non-map root should fail")
+	}
+}
@@ -1,21 +1,17 @@
 package json
 
 import (
-	"bytes"
 	"encoding/json"
-	"github.com/spf13/viper"
+
+	"github.com/ZhengHe-MD/agollo/v4/parse/internal/flatten"
 )
 
 // Parser json 转换器
 type Parser struct {
-	Vp *viper.Viper
 }
 
 func NewParser() *Parser {
-	p := Parser{
-		Vp: viper.New(),
-	}
-	p.Vp.SetConfigType("json")
+	p := Parser{}
 	return &p
 }
 
@@ -29,26 +25,12 @@ func (this *Parser) Parse(configContent interface{}) (map[string]interface{}, er
 		return nil, nil
 	}
 
-	buffer := bytes.NewBufferString(content)
-
-	err := this.Vp.ReadConfig(buffer)
-	if err != nil {
+	var root interface{}
+	if err := json.Unmarshal([]byte(content), &root); err != nil {
 		return nil, err
 	}
 
-	return this.convertToMap(), nil
-}
-
-func (this *Parser) convertToMap() map[string]interface{} {
-	if this.Vp == nil {
-		return nil
-	}
-
-	m := make(map[string]interface{})
-	for _, key := range this.Vp.AllKeys() {
-		m[key] = this.Vp.Get(key)
-	}
-	return m
+	return flatten.Flatten(root)
 }
 
 func (this *Parser) GetParserType() string {
@@ -1,6 +1,9 @@
 package parse
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/ZhengHe-MD/agollo/v4/parse/json"
 	"github.com/ZhengHe-MD/agollo/v4/parse/normal"
 	"github.com/ZhengHe-MD/agollo/v4/parse/properties"
@@ -8,23 +11,65 @@ import (
 	"github.com/ZhengHe-MD/agollo/v4/parse/yml"
 )
 
+// ContentParser parse content of file namespaces, it should be safe for
+// concurrent use since a registered parser is shared
 type ContentParser interface {
 	Parse(configContent interface{}) (map[string]interface{}, error)
 	GetParserType() string
 	Unmarshal(data []byte, val interface{}) error
 }
 
+var (
+	lock    sync.RWMutex
+	parsers = map[string]ContentParser{}
+)
+
+func init() {
+	Register("yml", yml.NewParser())
+	Register("yaml", yaml.NewParser())
+	Register("json", json.NewParser())
+	Register("properties", properties.NewParser())
+}
+
+// Register make parser used for namespaces with the suffix, e.g. "toml" for
+// namespace "app.toml", it replaces the parser registered before
+func Register(suffix string, parser ContentParser) {
+	if parser == nil {
+		panic("parse: Register parser is nil")
+	}
+	suffix = strings.TrimPrefix(suffix, ".")
+	if suffix == "" {
+		panic("parse: Register suffix is empty")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	parsers[suffix] = parser
+}
+
+// GetParser return parser registered for typ, or a parser which parses nothing
 func GetParser(typ string) ContentParser {
-	switch typ {
-	case "yml":
-		return yml.NewParser()
-	case "yaml":
-		return yaml.NewParser()
-	case "json":
-		return json.NewParser()
-	case "properties":
-		return properties.NewParser()
-	default:
-		return normal.NewParser()
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if parser, ok := parsers[typ]; ok {
+		return parser
+	}
+	return normal.NewParser()
+}
+
+// GetNamespaceType return the longest registered suffix of namespace, or empty
+// string if there is none
+func GetNamespaceType(namespace string) string {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	var ret string
+	for suffix := range parsers {
+		if len(suffix) > len(ret) && strings.HasSuffix(namespace, "."+suffix) {
+			ret = suffix
+		}
 	}
+	return ret
 }
@@ -0,0 +1,53 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/ZhengHe-MD/agollo/v4/parse/normal"
+)
+
+type mockParser struct {
+	normal.Parser
+}
+
+func (m *mockParser) GetParserType() string {
+	return "mock"
+}
+
+func TestRegister(t *testing.T) {
+	for _, typ := range []string{"yml", "yaml", "json", "properties"} {
+		if p := GetParser(typ); p.GetParserType() != typ {
+			t.Errorf("builtin parser %s should be registered, got:%s", typ, p.GetParserType())
+		}
+	}
+
+	if p := GetParser("mock"); p.GetParserType() != "normal" {
+		t.Errorf("unknown type should fall back to normal parser, got:%s", p.GetParserType())
+	}
+
+	Register(".mock", &mockParser{})
+	Register("tar.mock", &mockParser{})
+	if p := GetParser("mock"); p.GetParserType() != "mock" {
+		t.Errorf("registered parser should be returned, got:%s", p.GetParserType())
+	}
+
+	var tcs = []struct {
+		namespace string
+		expected  string
+	}{
+		{"application", ""},
+		{"service.yaml", "yaml"},
+		{"service.yml", "yml"},
+		{"client.json", "json"},
+		{"foo.properties", "properties"},
+		{"app.mock", "mock"},
+		{"app.tar.mock", "tar.mock"},
+		{"json", ""},
+		{"appjson", ""},
+	}
+	for _, tc := range tcs {
+		if typ := GetNamespaceType(tc.namespace); typ != tc.expected {
+			t.Errorf("namespace %s: type expected:%q got:%q", tc.namespace, tc.expected, typ)
+		}
+	}
+}
@@ -1,31 +1,218 @@
 package properties
 
 import (
-	"github.com/spf13/viper"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/mitchellh/mapstructure"
 )
 
 // Parser properties 转换器
 type Parser struct {
-	Vp *viper.Viper
 }
 
 func NewParser() *Parser {
-	p := Parser{
-		Vp: viper.New(),
-	}
-	p.Vp.SetConfigType("properties")
+	p := Parser{}
 	return &p
 }
 
 // Parse 内存内容 => properties 数据格式转换器
 func (d *Parser) Parse(configContent interface{}) (map[string]interface{}, error) {
-	return nil, nil
+	content, ok := configContent.(string)
+	if !ok {
+		return nil, nil
+	}
+	if content == "" {
+		return nil, nil
+	}
+
+	kv, err := Load(content)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{}, len(kv))
+	for k, v := range kv {
+		m[k] = v
+	}
+	return m, nil
 }
 
 func (this *Parser) GetParserType() string {
 	return "properties"
 }
 
+// Unmarshal decode properties into val, dotted keys are treated as nested
+// structs or maps, field names can be customized by `properties` tag
 func (this *Parser) Unmarshal(data []byte, val interface{}) error {
-	return nil
+	kv, err := Load(string(data))
+	if err != nil {
+		return err
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:          "properties",
+		WeaklyTypedInput: true,
+		Result:           val,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(nest(kv))
+}
+
+// nest turn dotted keys into nested maps, a leaf is dropped if it conflicts
+// with a nested key, e.g. "a" with "a.b"
+func nest(kv map[string]string) map[string]interface{} {
+	ret := map[string]interface{}{}
+	for k, v := range kv {
+		parts := strings.Split(k, ".")
+		m := ret
+		for _, part := range parts[:len(parts)-1] {
+			sub, ok := m[part].(map[string]interface{})
+			if !ok {
+				sub = map[string]interface{}{}
+				m[part] = sub
+			}
+			m = sub
+		}
+		last := parts[len(parts)-1]
+		if _, ok := m[last].(map[string]interface{}); !ok {
+			m[last] = v
+		}
+	}
+	return ret
+}
+
+// Load parse content in java properties format, see
+// https://docs.oracle.com/javase/8/docs/api/java/util/Properties.html#load-java.io.Reader-
+func Load(content string) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, line := range logicalLines(content) {
+		key, value := splitKeyValue(line)
+		k, err := unescape(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := unescape(value)
+		if err != nil {
+			return nil, err
+		}
+		ret[k] = v
+	}
+	return ret, nil
+}
+
+// logicalLines join continuation lines, skip blank and comment lines, leading
+// whitespaces are trimmed
+func logicalLines(content string) []string {
+	content = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(content)
+
+	var ret []string
+	var current strings.Builder
+	var continued bool
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimLeft(line, " \t\f")
+		if !continued && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		if endsWithContinuation(line) {
+			current.WriteString(line[:len(line)-1])
+			continued = true
+			continue
+		}
+
+		current.WriteString(line)
+		ret = append(ret, current.String())
+		current.Reset()
+		continued = false
+	}
+	if continued {
+		ret = append(ret, current.String())
+	}
+	return ret
+}
+
+// endsWithContinuation report whether line ends with odd number of backslashes
+func endsWithContinuation(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitKeyValue split line by the first unescaped '=', ':' or whitespace
+func splitKeyValue(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			value := strings.TrimLeft(line[i:], " \t\f")
+			if value != "" && (value[0] == '=' || value[0] == ':') {
+				value = strings.TrimLeft(value[1:], " \t\f")
+			}
+			return line[:i], value
+		}
+	}
+	return line, ""
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			break
+		}
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", errors.New("malformed \\uxxxx encoding")
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx encoding: %v", err)
+			}
+			i += 4
+			// characters out of BMP are escaped as surrogate pairs
+			if utf16.IsSurrogate(rune(r)) && strings.HasPrefix(s[i+1:], "\\u") && i+7 <= len(s) {
+				if r2, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil {
+					if pair := utf16.DecodeRune(rune(r), rune(r2)); pair != utf8.RuneError {
+						b.WriteRune(pair)
+						i += 6
+						continue
+					}
+				}
+			}
+			b.WriteRune(rune(r))
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
 }
@@ -0,0 +1,101 @@
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	content := "# comment\n" +
+		"! another comment\n" +
+		"\n" +
+		"a.b=c\n" +
+		"  key1 = value1  \n" +
+		"key2:value2\n" +
+		"key3 value3\n" +
+		"key4\t:  value4\n" +
+		"empty\n" +
+		"multi = line1, \\\n" +
+		"        line2\r\n" +
+		"escaped\\ key\\=x = tab\\tnew\\nline\\\\\n" +
+		"unicode = \\u4f60\\u597d\n" +
+		"emoji = \\uD83D\\uDE00 \\uD83D\\u0041\n" +
+		"# comment with continuation \\\n" +
+		"after = comment\n" +
+		"url=http://example.com:8080/path?a=b\n" +
+		"last = no newline \\"
+
+	kv, err := Load(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"a.b":           "c",
+		"key1":          "value1  ",
+		"key2":          "value2",
+		"key3":          "value3",
+		"key4":          "value4",
+		"empty":         "",
+		"multi":         "line1, line2",
+		"escaped key=x": "tab\tnew\nline\\",
+		"unicode":       "你好",
+		"emoji":         "\U0001F600 \uFFFDA",
+		"after":         "comment",
+		"url":           "http://example.com:8080/path?a=b",
+		"last":          "no newline ",
+	}
+	if !reflect.DeepEqual(kv, expected) {
+		t.Errorf("expected:%v got:%v", expected, kv)
+	}
+
+	if _, err := Load("bad = \\u12"); err == nil {
+		t.Errorf("malformed unicode escape should fail")
+	}
+}
+
+func TestParse(t *testing.T) {
+	p := NewParser()
+
+	if m, err := p.Parse(1); m != nil || err != nil {
+		t.Errorf("non string content should be ignored")
+	}
+
+	m, err := p.Parse("a.b=c\nd=e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, map[string]interface{}{"a.b": "c", "d": "e"}) {
+		t.Errorf("unexpected result:%v", m)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var val struct {
+		Name    string
+		Port    int
+		Debug   bool
+		Version string `properties:"ver"`
+		Ratio   float64
+		DB      struct {
+			Host string
+			Port int
+		}
+		Labels map[string]string
+	}
+
+	content := "name=agollo\nport=8080\ndebug=true\nver=v1\nratio=0.5\ndb.host=localhost\ndb.port=3306\nlabels.env=dev"
+	if err := NewParser().Unmarshal([]byte(content), &val); err != nil {
+		t.Fatal(err)
+	}
+
+	if val.Name != "agollo" || val.Port != 8080 || !val.Debug || val.Version != "v1" || val.Ratio != 0.5 {
+		t.Errorf("unexpected val:%+v", val)
+	}
+	if val.DB.Host != "localhost" || val.DB.Port != 3306 {
+		t.Errorf("unexpected val:%+v", val)
+	}
+	if val.Labels["env"] != "dev" {
+		t.Errorf("unexpected labels:%v", val.Labels)
+	}
+}
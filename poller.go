@@ -3,7 +3,8 @@ package agollo
 import (
 	"context"
 	"encoding/json"
-	"net/http"
+	"errors"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -16,15 +17,17 @@ type poller interface {
 	// start poll updates
 	start()
 	// preload fetch all config to local cache, and update all notifications
-	preload() error
-	// stop poll updates
-	stop()
+	preload(ctx context.Context) error
+	// stop poll updates, wait until polling goroutine exits or ctx is done
+	stop(ctx context.Context) error
 	// addNamespaces add new namespace and pump config data
-	addNamespaces(namespaces ...string) error
+	addNamespaces(ctx context.Context, namespaces ...string) error
+	// state return current state of poller
+	state() PollerState
 }
 
 // notificationHandler handle namespace update notification
-type notificationHandler func(namespace string) error
+type notificationHandler func(ctx context.Context, namespace string) error
 
 // longPoller implement poller interface
 type longPoller struct {
@@ -35,17 +38,24 @@ type longPoller struct {
 	cancel         context.CancelFunc
 	version        uint64
 	requester      requester
+	servers        *serverRepo
+	backoff        *backoff
 
 	notifications *notificationRepo
 	handler       notificationHandler
+
+	lock sync.Mutex
+	done chan struct{}
 }
 
 // newLongPoller create a Poller
-func newLongPoller(conf *Conf, interval time.Duration, handler notificationHandler) poller {
+func newLongPoller(conf *Conf, servers *serverRepo, interval time.Duration, handler notificationHandler) poller {
 	poller := &longPoller{
 		conf:           conf,
+		servers:        servers,
 		pollerInterval: interval,
-		requester:      newHTTPRequester(&http.Client{Timeout: longPollTimeout}),
+		backoff:        newBackoff(conf.Backoff, interval),
+		requester:      newHTTPRequester(newHTTPClient(conf, longPollTimeout), conf.AppID, conf.Secret),
 		notifications:  new(notificationRepo),
 		handler:        handler,
 	}
@@ -60,15 +70,26 @@ func newLongPoller(conf *Conf, interval time.Duration, handler notificationHandl
 }
 
 func (p *longPoller) start() {
-	go p.watchUpdates()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.done != nil {
+		return
+	}
+	p.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		p.watchUpdates()
+	}(p.done)
 }
 
-func (p *longPoller) preload() error {
-	return p.pumpUpdates()
+func (p *longPoller) preload(ctx context.Context) error {
+	return p.pumpUpdates(ctx)
 }
 
 // addNamespaces subscribe to new namespaces and pull all config data to local
-func (p *longPoller) addNamespaces(namespaces ...string) error {
+func (p *longPoller) addNamespaces(ctx context.Context, namespaces ...string) error {
 	var update bool
 	for _, namespace := range namespaces {
 		if p.notifications.addNotificationID(namespace, defaultNotificationID) {
@@ -76,7 +97,7 @@ func (p *longPoller) addNamespaces(namespaces ...string) error {
 		}
 	}
 	if update {
-		return p.pumpUpdates()
+		return p.pumpUpdates(ctx)
 	}
 	return nil
 }
@@ -88,10 +109,12 @@ func (p *longPoller) watchUpdates() {
 	for {
 		select {
 		case <-timer.C:
-			if err := p.pumpUpdates(); err != nil {
-				defaultLogger.Printf("module:agollo method:watchUpdates err:%v", err)
+			err := p.pumpUpdates(p.ctx)
+			delay := p.backoff.next(err)
+			if err != nil {
+				defaultLogger.Printf("module:agollo method:watchUpdates err:%v retry after:%v", err, delay)
 			}
-			timer.Reset(p.pollerInterval)
+			timer.Reset(delay)
 
 		case <-p.ctx.Done():
 			return
@@ -99,8 +122,27 @@ func (p *longPoller) watchUpdates() {
 	}
 }
 
-func (p *longPoller) stop() {
+func (p *longPoller) stop(ctx context.Context) error {
 	p.cancel()
+
+	p.lock.Lock()
+	done := p.done
+	p.lock.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *longPoller) state() PollerState {
+	return p.backoff.getState()
 }
 
 func (p *longPoller) updateNotificationConf(notification *notification) {
@@ -108,14 +150,14 @@ func (p *longPoller) updateNotificationConf(notification *notification) {
 }
 
 // pumpUpdates fetch updated namespace, handle updated namespace then update notification id
-func (p *longPoller) pumpUpdates() error {
+func (p *longPoller) pumpUpdates(ctx context.Context) error {
 	// serialize pumpUpdates request
 
 	version := atomic.AddUint64(&p.version, 1)
 
 	var ret error
 
-	updates, err := p.poll()
+	updates, err := p.poll(ctx)
 	if err != nil {
 		return err
 	}
@@ -125,7 +167,7 @@ func (p *longPoller) pumpUpdates() error {
 	}
 
 	for _, update := range updates {
-		if err := p.handler(update.NamespaceName); err != nil {
+		if err := p.handler(ctx, update.NamespaceName); err != nil {
 			ret = err
 			continue
 		}
@@ -136,13 +178,19 @@ func (p *longPoller) pumpUpdates() error {
 }
 
 // poll until a update or timeout
-func (p *longPoller) poll() ([]*notification, error) {
+func (p *longPoller) poll(ctx context.Context) ([]*notification, error) {
 	notifications := p.notifications.toString()
-	url := notificationURL(p.conf, notifications)
-	defaultLogger.Printf("module:agollo method:longPoller.poll url:%s start", url)
-	bts, err := p.requester.request(url)
-	defaultLogger.Printf("module:agollo method:longPoller.poll url:%s finish with data:%s err:%v", url, bts, err)
-	if err != nil || len(bts) == 0 {
+	bts, err := p.servers.do(ctx, func(server string) ([]byte, error) {
+		url := notificationURL(server, p.conf, notifications)
+		defaultLogger.Printf("module:agollo method:longPoller.poll url:%s start", url)
+		bts, err := p.requester.request(ctx, url)
+		defaultLogger.Printf("module:agollo method:longPoller.poll url:%s finish with data:%s err:%v", url, bts, err)
+		return bts, err
+	})
+	if errors.Is(err, ErrNotModified) {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
 	var ret []*notification
@@ -1,30 +1,59 @@
 package agollo
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 // this is a static check
 var _ requester = (*httprequester)(nil)
 
 type requester interface {
-	request(url string) ([]byte, error)
+	request(ctx context.Context, url string) ([]byte, error)
 }
 
 type httprequester struct {
 	client *http.Client
+	appID  string
+	secret string
 }
 
-func newHTTPRequester(client *http.Client) requester {
+// newHTTPClient create http client with timeout, base on Conf.HTTPClient and
+// Conf.Transport if given
+func newHTTPClient(conf *Conf, timeout time.Duration) *http.Client {
+	var client http.Client
+	if conf.HTTPClient != nil {
+		client = *conf.HTTPClient
+	}
+	if conf.Transport != nil {
+		client.Transport = conf.Transport
+	}
+	client.Timeout = timeout
+	return &client
+}
+
+// newHTTPRequester create a requester, requests will be signed if secret is not empty
+func newHTTPRequester(client *http.Client, appID, secret string) requester {
 	return &httprequester{
 		client: client,
+		appID:  appID,
+		secret: secret,
 	}
 }
 
-func (r *httprequester) request(url string) ([]byte, error) {
-	resp, err := r.client.Get(url)
+func (r *httprequester) request(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.secret != "" {
+		signRequest(req, r.appID, r.secret)
+	}
+
+	resp, err := r.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -36,5 +65,5 @@ func (r *httprequester) request(url string) ([]byte, error) {
 
 	// Diacard all body if status code is not 200
 	io.Copy(ioutil.Discard, resp.Body)
-	return nil, nil
+	return nil, &StatusError{URL: url, StatusCode: resp.StatusCode}
 }
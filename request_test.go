@@ -2,19 +2,22 @@ package agollo
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestRequest(t *testing.T) {
-	request := newHTTPRequester(&http.Client{})
+	request := newHTTPRequester(&http.Client{}, "", "")
 
 	serv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Write([]byte("test"))
 	}))
 
-	bts, err := request.request(serv.URL)
+	bts, err := request.request(context.Background(), serv.URL)
 	if err != nil {
 		t.Error(err)
 	}
@@ -26,9 +29,9 @@ func TestRequest(t *testing.T) {
 	serv = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusInternalServerError)
 	}))
-	bts, err = request.request(serv.URL)
-	if err != nil {
-		t.Error(err)
+	bts, err = request.request(context.Background(), serv.URL)
+	if !errors.Is(err, ErrServerError) {
+		t.Errorf("expected server error, got:%v", err)
 	}
 
 	if len(bts) != 0 {
@@ -39,8 +42,63 @@ func TestRequest(t *testing.T) {
 		rw.WriteHeader(http.StatusInternalServerError)
 	}))
 	serv.Close()
-	_, err = request.request(serv.URL)
+	_, err = request.request(context.Background(), serv.URL)
 	if err == nil {
 		t.FailNow()
 	}
 }
+
+type countingTransport struct {
+	count int
+	next  http.RoundTripper
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.count++
+	return c.next.RoundTrip(req)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	client := newHTTPClient(&Conf{}, queryTimeout)
+	if client.Timeout != queryTimeout || client.Transport != nil {
+		t.Errorf("unexpected default client:%+v", client)
+	}
+
+	template := &http.Client{Timeout: time.Hour}
+	transport := &countingTransport{next: http.DefaultTransport}
+	client = newHTTPClient(&Conf{HTTPClient: template, Transport: transport}, longPollTimeout)
+	if client == template || client.Timeout != longPollTimeout || client.Transport != transport {
+		t.Errorf("unexpected client:%+v", client)
+	}
+	if template.Timeout != time.Hour || template.Transport != nil {
+		t.Errorf("template client should not be modified")
+	}
+}
+
+func TestHTTPSRequest(t *testing.T) {
+	serv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(`{"namespaceName":"application","configurations":{"key":"val"},"releaseKey":"1"}`))
+	}))
+	defer serv.Close()
+
+	transport := &countingTransport{next: serv.Client().Transport}
+	c := NewClient(&Conf{
+		AppID:     "SampleApp",
+		Cluster:   "default",
+		CacheDir:  "/tmp/agollo",
+		IP:        serv.URL,
+		Transport: transport,
+	})
+
+	if _, err := c.sync(context.Background(), defaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := c.GetString("key"); !ok || v != "val" {
+		t.Errorf("config should be fetched over https, got:%v %v", v, ok)
+	}
+
+	if transport.count != 1 {
+		t.Errorf("custom transport should be used, got %d round trips", transport.count)
+	}
+}
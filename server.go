@@ -0,0 +1,209 @@
+package agollo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errNoServer = errors.New("no available config server")
+
+// serviceDTO is a config service instance returned by meta server
+type serviceDTO struct {
+	AppName     string `json:"appName"`
+	InstanceID  string `json:"instanceId"`
+	HomepageURL string `json:"homepageUrl"`
+}
+
+// serverRepo keep config service instances, discovered from meta server or
+// configured by Conf.IP, and rotate between them when request fails
+type serverRepo struct {
+	conf      *Conf
+	requester requester
+	cooldown  time.Duration
+
+	lock     sync.Mutex
+	servers  []string
+	next     int
+	failedAt map[string]time.Time
+}
+
+func newServerRepo(conf *Conf, requester requester, cooldown time.Duration) *serverRepo {
+	return &serverRepo{
+		conf:      conf,
+		requester: requester,
+		cooldown:  cooldown,
+		servers:   splitAddrs(conf.IP),
+		failedAt:  map[string]time.Time{},
+	}
+}
+
+// refresh fetch config service instances from meta server, keep the old ones
+// if failed
+func (s *serverRepo) refresh(ctx context.Context) error {
+	if s.conf.MetaAddr == "" {
+		return nil
+	}
+
+	var err error
+	for _, meta := range splitAddrs(s.conf.MetaAddr) {
+		var servers []string
+		if servers, err = s.discover(ctx, meta); err != nil {
+			continue
+		}
+		if len(servers) == 0 {
+			err = errNoServer
+			continue
+		}
+		s.setServers(servers)
+		return nil
+	}
+
+	defaultLogger.Printf("module:agollo method:serverRepo.refresh meta:%s err:%v", s.conf.MetaAddr, err)
+	return err
+}
+
+func (s *serverRepo) discover(ctx context.Context, meta string) ([]string, error) {
+	bts, err := s.requester.request(ctx, servicesURL(meta, s.conf))
+	if err != nil {
+		return nil, err
+	}
+
+	var services []*serviceDTO
+	if err := json.Unmarshal(bts, &services); err != nil {
+		return nil, err
+	}
+
+	var servers []string
+	for _, service := range services {
+		if service.HomepageURL != "" {
+			servers = append(servers, strings.TrimSuffix(service.HomepageURL, "/"))
+		}
+	}
+	return servers, nil
+}
+
+// watch refresh servers periodically until ctx is done
+func (s *serverRepo) watch(ctx context.Context, interval time.Duration) {
+	if s.conf.MetaAddr == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.refresh(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *serverRepo) setServers(servers []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.servers = servers
+	s.next = 0
+	for server := range s.failedAt {
+		if !contains(servers, server) {
+			delete(s.failedAt, server)
+		}
+	}
+}
+
+// candidates return servers in trying order, healthy servers go first, starting
+// from the last succeeded one, servers in cooldown are kept as a last resort
+func (s *serverRepo) candidates() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var healthy, cooling []string
+	now := time.Now()
+	for i := range s.servers {
+		server := s.servers[(s.next+i)%len(s.servers)]
+		if failedAt, ok := s.failedAt[server]; ok && now.Sub(failedAt) < s.cooldown {
+			cooling = append(cooling, server)
+			continue
+		}
+		healthy = append(healthy, server)
+	}
+	return append(healthy, cooling...)
+}
+
+func (s *serverRepo) markFailed(server string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.failedAt[server] = time.Now()
+}
+
+func (s *serverRepo) markSucceeded(server string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.failedAt, server)
+	for i, v := range s.servers {
+		if v == server {
+			s.next = i
+			return
+		}
+	}
+}
+
+// do call fn with servers one by one until it succeeds, or fails with an
+// error which is not a server failure, or ctx is done
+func (s *serverRepo) do(ctx context.Context, fn func(server string) ([]byte, error)) ([]byte, error) {
+	var err = errNoServer
+	for _, server := range s.candidates() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var bts []byte
+		bts, err = fn(server)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if isServerFailure(err) {
+			s.markFailed(server)
+			continue
+		}
+		s.markSucceeded(server)
+		return bts, err
+	}
+	return nil, err
+}
+
+// splitAddrs split comma separated addresses into server urls
+func splitAddrs(addrs string) []string {
+	var ret []string
+	for _, addr := range strings.Split(addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			ret = append(ret, normalizeAddr(addr))
+		}
+	}
+	return ret
+}
+
+// normalizeAddr turn address into server url, use http if no scheme is given
+func normalizeAddr(addr string) string {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return strings.TrimSuffix(addr, "/")
+}
+
+func contains(strs []string, str string) bool {
+	for _, v := range strs {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package agollo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSplitAddrs(t *testing.T) {
+	servers := splitAddrs(" 127.0.0.1:8080, 127.0.0.2:8080/ ,https://apollo.example.com/")
+	expected := []string{"http://127.0.0.1:8080", "http://127.0.0.2:8080", "https://apollo.example.com"}
+	if !reflect.DeepEqual(servers, expected) {
+		t.Errorf("servers expected:%v got:%v", expected, servers)
+	}
+}
+
+func TestServerRepoRefresh(t *testing.T) {
+	repo := newServerRepo(&Conf{AppID: "SampleApp", MetaAddr: "localhost:8080"},
+		newHTTPRequester(&http.Client{Timeout: queryTimeout}, "", ""), serverCooldown)
+
+	if err := repo.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if servers := repo.candidates(); !reflect.DeepEqual(servers, []string{"http://localhost:8080"}) {
+		t.Errorf("unexpected servers:%v", servers)
+	}
+
+	repo.conf.MetaAddr = "localhost:1"
+	if err := repo.refresh(context.Background()); err == nil {
+		t.Errorf("refresh from dead meta server should fail")
+	}
+
+	if servers := repo.candidates(); !reflect.DeepEqual(servers, []string{"http://localhost:8080"}) {
+		t.Errorf("servers should be kept when refresh failed, got:%v", servers)
+	}
+}
+
+func TestServerRepoFailover(t *testing.T) {
+	repo := newServerRepo(&Conf{IP: "s1,s2,s3"}, nil, time.Minute)
+
+	var tried []string
+	bts, err := repo.do(context.Background(), func(server string) ([]byte, error) {
+		tried = append(tried, server)
+		if server == "http://s3" {
+			return []byte("ok"), nil
+		}
+		return nil, errors.New("dead")
+	})
+	if err != nil || string(bts) != "ok" {
+		t.Fatalf("do should succeed with the last server, got:%s %v", bts, err)
+	}
+	if !reflect.DeepEqual(tried, []string{"http://s1", "http://s2", "http://s3"}) {
+		t.Errorf("unexpected trying order:%v", tried)
+	}
+
+	expected := []string{"http://s3", "http://s1", "http://s2"}
+	if servers := repo.candidates(); !reflect.DeepEqual(servers, expected) {
+		t.Errorf("failed servers should cool down, expected:%v got:%v", expected, servers)
+	}
+
+	repo.markSucceeded("http://s1")
+	expected = []string{"http://s1", "http://s3", "http://s2"}
+	if servers := repo.candidates(); !reflect.DeepEqual(servers, expected) {
+		t.Errorf("succeeded server should leave cooldown, expected:%v got:%v", expected, servers)
+	}
+
+	repo.cooldown = 0
+	expected = []string{"http://s1", "http://s2", "http://s3"}
+	if servers := repo.candidates(); !reflect.DeepEqual(servers, expected) {
+		t.Errorf("servers should be retried after cooldown, expected:%v got:%v", expected, servers)
+	}
+
+	if _, err := newServerRepo(&Conf{}, nil, time.Minute).do(context.Background(), nil); err != errNoServer {
+		t.Errorf("do without servers should return errNoServer, got:%v", err)
+	}
+}
+
+func TestClientWithMetaServer(t *testing.T) {
+	c := NewClient(&Conf{
+		AppID:          "SampleApp",
+		Cluster:        "default",
+		NameSpaceNames: []string{defaultNamespace},
+		CacheDir:       "/tmp/agollo",
+		MetaAddr:       "localhost:8080",
+	})
+	if err := c.Start(); err != nil {
+		t.Error(err)
+	}
+	defer c.Stop()
+
+	if servers := c.servers.candidates(); !reflect.DeepEqual(servers, []string{"http://localhost:8080"}) {
+		t.Errorf("unexpected servers:%v", servers)
+	}
+}
+
+func TestWatchServersOnce(t *testing.T) {
+	c := NewClient(&Conf{AppID: "SampleApp", MetaAddr: "127.0.0.1:1", CacheStore: NewNoopCacheStore()})
+	defer c.Stop()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 3; i++ {
+		c.watchServers(context.Background())
+	}
+	time.Sleep(time.Millisecond * 10)
+	if n := runtime.NumGoroutine() - before; n != 1 {
+		t.Errorf("meta server should be watched by one goroutine, got:%d", n)
+	}
+}
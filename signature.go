@@ -0,0 +1,40 @@
+package agollo
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	timestampHeader     = "Timestamp"
+)
+
+// signature calculate apollo access key signature, see
+// https://www.apolloconfig.com/#/zh/usage/other-language-client-user-guide
+func signature(timestamp, pathWithQuery, secret string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + pathWithQuery))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signRequest add Authorization and Timestamp headers to req
+func signRequest(req *http.Request, appID, secret string) {
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	req.Header.Set(authorizationHeader, fmt.Sprintf("Apollo %s:%s", appID, signature(timestamp, pathWithQuery(req.URL), secret)))
+	req.Header.Set(timestampHeader, timestamp)
+}
+
+func pathWithQuery(u *url.URL) string {
+	ret := u.EscapedPath()
+	if u.RawQuery != "" {
+		ret += "?" + u.RawQuery
+	}
+	return ret
+}
@@ -0,0 +1,69 @@
+package agollo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ZhengHe-MD/agollo/v4/internal/mockserver"
+)
+
+func TestPathWithQuery(t *testing.T) {
+	u, _ := url.Parse("http://localhost:8080/configs/SampleApp/default/application?releaseKey=&ip=127.0.0.1")
+	if ret := pathWithQuery(u); ret != "/configs/SampleApp/default/application?releaseKey=&ip=127.0.0.1" {
+		t.Errorf("unexpected path with query: %s", ret)
+	}
+
+	u, _ = url.Parse("http://localhost:8080/services/config")
+	if ret := pathWithQuery(u); ret != "/services/config" {
+		t.Errorf("unexpected path with query: %s", ret)
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/configs/SampleApp/default/application", nil)
+	signRequest(req, "SampleApp", "secret")
+
+	timestamp := req.Header.Get(timestampHeader)
+	if timestamp == "" {
+		t.Fatal("timestamp header should be set")
+	}
+	expected := "Apollo SampleApp:" + signature(timestamp, "/configs/SampleApp/default/application", "secret")
+	if auth := req.Header.Get(authorizationHeader); auth != expected {
+		t.Errorf("authorization expected:%s got:%s", expected, auth)
+	}
+}
+
+func TestSignedRequest(t *testing.T) {
+	mockserver.Set(defaultNamespace, "signed", "val")
+	mockserver.SetSecret("secret")
+	defer mockserver.SetSecret("")
+
+	conf := *defaultConf
+	url := configURL("http://"+conf.IP, &conf, defaultNamespace, "")
+
+	if _, err := newHTTPRequester(&http.Client{}, conf.AppID, "").request(context.Background(), url); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("unsigned request should be rejected, got err:%v", err)
+	}
+
+	if _, err := newHTTPRequester(&http.Client{}, conf.AppID, "wrong").request(context.Background(), url); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("wrongly signed request should be rejected, got err:%v", err)
+	}
+
+	if bts, err := newHTTPRequester(&http.Client{}, conf.AppID, "secret").request(context.Background(), url); err != nil || len(bts) == 0 {
+		t.Errorf("signed request should be accepted, got err:%v", err)
+	}
+
+	conf.Secret = "secret"
+	c := NewClient(&conf)
+	if err := c.Start(); err != nil {
+		t.Error(err)
+	}
+	defer c.Stop()
+
+	if v, ok := c.GetString("signed"); !ok || v != "val" {
+		t.Errorf("signed client should fetch config, got:%v %v", v, ok)
+	}
+}
@@ -0,0 +1,78 @@
+package agollo
+
+import (
+	"sort"
+)
+
+// Snapshot is an immutable view of all values of a namespace at a release
+type Snapshot struct {
+	namespace  string
+	releaseKey string
+	values     map[string]interface{}
+}
+
+// newSnapshot take ownership of values, which must not be modified later
+func newSnapshot(namespace, releaseKey string, values map[string]interface{}) *Snapshot {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return &Snapshot{namespace: namespace, releaseKey: releaseKey, values: values}
+}
+
+// Namespace return name of the namespace
+func (s *Snapshot) Namespace() string {
+	return s.namespace
+}
+
+// ReleaseKey return release key of the snapshot, it's empty for chains
+func (s *Snapshot) ReleaseKey() string {
+	return s.releaseKey
+}
+
+// Get return raw value of key, maps and slices of file namespaces are
+// copied, so the snapshot is never modified by callers
+func (s *Snapshot) Get(key string) (interface{}, bool) {
+	val, ok := s.values[key]
+	return copyValue(val), ok
+}
+
+// Keys return all keys in order
+func (s *Snapshot) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Values return a deep copy of all values
+func (s *Snapshot) Values() map[string]interface{} {
+	return copyValue(s.values).(map[string]interface{})
+}
+
+// copyValue deep copy maps and slices parsed from file namespaces, other
+// values are immutable and returned as is
+func copyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			ret[k] = copyValue(item)
+		}
+		return ret
+	case map[interface{}]interface{}:
+		ret := make(map[interface{}]interface{}, len(v))
+		for k, item := range v {
+			ret[k] = copyValue(item)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = copyValue(item)
+		}
+		return ret
+	}
+	return val
+}
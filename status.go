@@ -0,0 +1,150 @@
+package agollo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Source is where config of a namespace comes from
+type Source int
+
+const (
+	// SourceNone means config is missing
+	SourceNone Source = iota
+	// SourceRemote means config is fetched from apollo
+	SourceRemote
+	// SourceCache means config is loaded from local dump file
+	SourceCache
+	// SourceLocal means config is provided locally, instead of apollo or
+	// dump files
+	SourceLocal
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceNone:
+		return "NONE"
+	case SourceRemote:
+		return "REMOTE"
+	case SourceCache:
+		return "CACHE"
+	case SourceLocal:
+		return "LOCAL"
+	}
+
+	return "UNKNOW"
+}
+
+// NamespaceStatus report whether a namespace is usable
+type NamespaceStatus struct {
+	Namespace  string
+	Source     Source
+	ReleaseKey string
+	// UpdatedAt is when config is last confirmed from Source
+	UpdatedAt time.Time
+}
+
+// Loaded report whether namespace has a remote or cached value
+func (s NamespaceStatus) Loaded() bool {
+	return s.Source != SourceNone
+}
+
+type statusRepo struct {
+	lock     sync.Mutex
+	statuses map[string]*NamespaceStatus
+	// changed is closed and replaced whenever a status changes
+	changed chan struct{}
+}
+
+func newStatusRepo() *statusRepo {
+	return &statusRepo{
+		statuses: map[string]*NamespaceStatus{},
+		changed:  make(chan struct{}),
+	}
+}
+
+// track add namespaces as missing if they are unknown
+func (r *statusRepo) track(namespaces ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, namespace := range namespaces {
+		if _, ok := r.statuses[namespace]; !ok {
+			r.statuses[namespace] = &NamespaceStatus{Namespace: namespace}
+		}
+	}
+}
+
+func (r *statusRepo) set(namespace string, source Source, releaseKey string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.statuses[namespace] = &NamespaceStatus{
+		Namespace:  namespace,
+		Source:     source,
+		ReleaseKey: releaseKey,
+		UpdatedAt:  time.Now(),
+	}
+
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (r *statusRepo) get(namespace string) (NamespaceStatus, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if status, ok := r.statuses[namespace]; ok {
+		return *status, true
+	}
+	return NamespaceStatus{Namespace: namespace}, false
+}
+
+// all return statuses sorted by namespace
+func (r *statusRepo) all() []NamespaceStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var ret []NamespaceStatus
+	for _, status := range r.statuses {
+		ret = append(ret, *status)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Namespace < ret[j].Namespace
+	})
+	return ret
+}
+
+// missing return namespaces without value and a channel closed on next change
+func (r *statusRepo) missing() ([]string, <-chan struct{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var ret []string
+	for namespace, status := range r.statuses {
+		if !status.Loaded() {
+			ret = append(ret, namespace)
+		}
+	}
+	sort.Strings(ret)
+	return ret, r.changed
+}
+
+// wait until all namespaces are loaded or ctx is done
+func (r *statusRepo) wait(ctx context.Context) error {
+	for {
+		missing, changed := r.missing()
+		if len(missing) == 0 {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return fmt.Errorf("namespaces %v not ready: %w", missing, ctx.Err())
+		}
+	}
+}
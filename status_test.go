@@ -0,0 +1,97 @@
+package agollo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZhengHe-MD/agollo/v4/internal/mockserver"
+)
+
+func TestSource(t *testing.T) {
+	var sources = []Source{SourceNone, SourceRemote, SourceCache, Source(-1)}
+	var strs = []string{"NONE", "REMOTE", "CACHE", "UNKNOW"}
+	for i, source := range sources {
+		if source.String() != strs[i] {
+			t.FailNow()
+		}
+	}
+}
+
+func TestStatusRepo(t *testing.T) {
+	repo := newStatusRepo()
+	repo.track("ns1", "ns2")
+
+	if status, ok := repo.get("ns1"); !ok || status.Loaded() {
+		t.Errorf("tracked namespace should be missing, got:%+v", status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := repo.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait should time out, got:%v", err)
+	}
+
+	go func() {
+		repo.set("ns1", SourceRemote, "key1")
+		repo.set("ns2", SourceCache, "")
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := repo.wait(ctx); err != nil {
+		t.Errorf("wait should succeed, got:%v", err)
+	}
+
+	statuses := repo.all()
+	if len(statuses) != 2 || statuses[0].Namespace != "ns1" || statuses[1].Namespace != "ns2" {
+		t.Fatalf("unexpected statuses:%+v", statuses)
+	}
+	if statuses[0].Source != SourceRemote || statuses[0].ReleaseKey != "key1" || statuses[0].UpdatedAt.IsZero() {
+		t.Errorf("unexpected status:%+v", statuses[0])
+	}
+	if statuses[1].Source != SourceCache {
+		t.Errorf("unexpected status:%+v", statuses[1])
+	}
+
+	repo.track("ns1")
+	if status, _ := repo.get("ns1"); status.Source != SourceRemote {
+		t.Errorf("track should not reset known namespace, got:%+v", status)
+	}
+}
+
+func TestClientReady(t *testing.T) {
+	mockserver.Set(defaultNamespace, "ready", "true")
+
+	c := NewClient(defaultConf)
+	if err := c.Start(); err != nil {
+		t.Error(err)
+	}
+	defer c.Stop()
+
+	if err := c.WaitReady(time.Second); err != nil {
+		t.Errorf("client should be ready, got:%v", err)
+	}
+
+	status, ok := c.GetNamespaceStatus(defaultNamespace)
+	if !ok || status.Source != SourceRemote || status.ReleaseKey == "" {
+		t.Errorf("unexpected status:%+v", status)
+	}
+
+	// local file never overrides remote
+	if err := c.loadLocal(defaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := c.GetNamespaceStatus(defaultNamespace); status.Source != SourceRemote {
+		t.Errorf("status should be remote after loading local file, got:%+v", status)
+	}
+
+	restore := NewClient(defaultConf)
+	if err := restore.loadLocal(defaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := restore.GetNamespaceStatus(defaultNamespace); cached.Source != SourceCache || cached.ReleaseKey != status.ReleaseKey {
+		t.Errorf("status should be cache after loading local file, got:%+v", cached)
+	}
+}
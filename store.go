@@ -0,0 +1,90 @@
+package agollo
+
+import (
+	"sync"
+	"time"
+)
+
+// CacheEntry is the local cache of a namespace
+type CacheEntry struct {
+	Namespace  string    `json:"namespace"`
+	ReleaseKey string    `json:"releaseKey"`
+	FetchTime  time.Time `json:"fetchTime"`
+	// Configurations is returned by apollo, before parsing
+	Configurations map[string]interface{} `json:"configurations,omitempty"`
+	// Values is parsed already, it's used by entries migrated from the
+	// legacy gob dump, whose original content is unknown
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+// CacheStore save namespaces fetched from apollo, they are loaded when apollo
+// is unavailable. It should be safe for concurrent use
+type CacheStore interface {
+	// Load return entry of namespace, ErrCacheNotFound is returned if there
+	// is none
+	Load(namespace string) (*CacheEntry, error)
+	// Save replace entry of namespace
+	Save(entry *CacheEntry) error
+	// Delete remove entry of namespace, it's not an error if there is none
+	Delete(namespace string) error
+}
+
+// memoryCacheStore keep entries in memory only
+type memoryCacheStore struct {
+	lock    sync.RWMutex
+	entries map[string]CacheEntry
+}
+
+// NewMemoryCacheStore create a CacheStore keeping entries in memory only,
+// e.g. for tests, or read-only filesystems
+func NewMemoryCacheStore() CacheStore {
+	return &memoryCacheStore{entries: map[string]CacheEntry{}}
+}
+
+func (s *memoryCacheStore) Load(namespace string) (*CacheEntry, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	entry, ok := s.entries[namespace]
+	if !ok {
+		return nil, ErrCacheNotFound
+	}
+	return &entry, nil
+}
+
+func (s *memoryCacheStore) Save(entry *CacheEntry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entries[entry.Namespace] = *entry
+	return nil
+}
+
+func (s *memoryCacheStore) Delete(namespace string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.entries, namespace)
+	return nil
+}
+
+// noopCacheStore save nothing
+type noopCacheStore struct{}
+
+// NewNoopCacheStore create a CacheStore saving nothing, namespaces failed to
+// fetch are not available at all
+func NewNoopCacheStore() CacheStore {
+	return noopCacheStore{}
+}
+
+func (noopCacheStore) Load(namespace string) (*CacheEntry, error) {
+	return nil, ErrCacheNotFound
+}
+
+func (noopCacheStore) Save(entry *CacheEntry) error {
+	return nil
+}
+
+func (noopCacheStore) Delete(namespace string) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package agollo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryCacheStore(t *testing.T) {
+	store := NewMemoryCacheStore()
+	if _, err := store.Load(defaultNamespace); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("expected ErrCacheNotFound, got:%v", err)
+	}
+
+	c := NewClient(&Conf{AppID: "StoreApp", CacheDir: "/nonexistent", CacheStore: store})
+	c.handleResult(&result{NamespaceName: defaultNamespace, ReleaseKey: "r1", Configurations: map[string]interface{}{"key": "val"}})
+
+	entry, err := store.Load(defaultNamespace)
+	if err != nil || entry.ReleaseKey != "r1" || entry.Configurations["key"] != "val" {
+		t.Fatalf("unexpected entry:%+v %v", entry, err)
+	}
+
+	restore := NewClient(&Conf{AppID: "StoreApp", CacheStore: store})
+	if err := restore.loadLocal(defaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := restore.GetString("key"); !ok || v != "val" {
+		t.Errorf("key expected:val got:%v %v", v, ok)
+	}
+
+	if err := store.Delete(defaultNamespace); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(defaultNamespace); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("expected ErrCacheNotFound after delete, got:%v", err)
+	}
+}
+
+func TestNoopCacheStore(t *testing.T) {
+	store := NewNoopCacheStore()
+	c := NewClient(&Conf{AppID: "StoreApp", CacheStore: store})
+	c.handleResult(&result{NamespaceName: defaultNamespace, ReleaseKey: "r1", Configurations: map[string]interface{}{"key": "val"}})
+
+	if err := NewClient(c.conf).loadLocal(defaultNamespace); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("expected ErrCacheNotFound, got:%v", err)
+	}
+	if err := store.Delete(defaultNamespace); err != nil {
+		t.Error(err)
+	}
+}
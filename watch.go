@@ -0,0 +1,83 @@
+package agollo
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyChangeFunc is called with key, old and new value of a changed key, old
+// is nil for added keys, and new is nil for deleted keys
+type KeyChangeFunc func(key string, old, new interface{})
+
+// keyWatcher is an observer calls fn for changed keys of namespace that
+// match
+type keyWatcher struct {
+	namespace string
+	match     func(key string) bool
+	fn        KeyChangeFunc
+}
+
+func (w *keyWatcher) HandleChangeEvent(event *ChangeEvent) {
+	if event.Namespace != w.namespace {
+		return
+	}
+
+	var keys []string
+	for key := range event.Changes {
+		if w.match(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		change := event.Changes[key]
+		w.fn(key, change.OldValue, change.NewValue)
+	}
+}
+
+// watch register w as an observer, return a func to unregister it
+func (c *Client) watch(w *keyWatcher) (unsubscribe func()) {
+	c.registerObserver(w)
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.recallObserver(w) })
+	}
+}
+
+// WatchKey call fn with old and new value when key of namespace changes,
+// change events are delivered by StartWatchUpdate
+func (c *Client) WatchKey(namespace, key string, fn func(old, new interface{})) (unsubscribe func()) {
+	return c.watch(&keyWatcher{
+		namespace: namespace,
+		match:     func(k string) bool { return k == key },
+		fn:        func(_ string, old, new interface{}) { fn(old, new) },
+	})
+}
+
+// WatchPrefix call fn when keys of namespace with prefix change
+func (c *Client) WatchPrefix(namespace, prefix string, fn KeyChangeFunc) (unsubscribe func()) {
+	return c.watch(&keyWatcher{
+		namespace: namespace,
+		match:     func(k string) bool { return strings.HasPrefix(k, prefix) },
+		fn:        fn,
+	})
+}
+
+// WatchGlob call fn when keys of namespace matching pattern change, see
+// path.Match for the pattern syntax, e.g. "db.*.host"
+func (c *Client) WatchGlob(namespace, pattern string, fn KeyChangeFunc) (unsubscribe func(), err error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return c.watch(&keyWatcher{
+		namespace: namespace,
+		match: func(k string) bool {
+			ok, _ := path.Match(pattern, k)
+			return ok
+		},
+		fn: fn,
+	}), nil
+}
@@ -0,0 +1,65 @@
+package agollo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWatch(t *testing.T) {
+	c := NewClient(&Conf{AppID: "WatchApp", CacheDir: "/tmp/agollo"})
+
+	var keyChanges [][2]interface{}
+	unsubscribeKey := c.WatchKey(defaultNamespace, "timeout", func(old, new interface{}) {
+		keyChanges = append(keyChanges, [2]interface{}{old, new})
+	})
+
+	var prefixKeys []string
+	unsubscribePrefix := c.WatchPrefix(defaultNamespace, "db.", func(key string, old, new interface{}) {
+		prefixKeys = append(prefixKeys, key)
+	})
+
+	var globKeys []string
+	unsubscribeGlob, err := c.WatchGlob(defaultNamespace, "db.*.host", func(key string, old, new interface{}) {
+		globKeys = append(globKeys, key)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WatchGlob(defaultNamespace, "[", nil); err == nil {
+		t.Errorf("bad pattern should fail")
+	}
+
+	deliver := func(event *ChangeEvent) {
+		for _, ob := range c.getObservers() {
+			ob.HandleChangeEvent(event)
+		}
+	}
+
+	deliver(&ChangeEvent{Namespace: defaultNamespace, Changes: map[string]*Change{
+		"timeout":        makeModifyChange("timeout", "1s", "2s"),
+		"db.master.host": makeAddChange("db.master.host", "m"),
+		"db.port":        makeDeleteChange("db.port", "3306"),
+		"name":           makeAddChange("name", "agollo"),
+	}})
+	deliver(&ChangeEvent{Namespace: "other", Changes: map[string]*Change{
+		"timeout": makeAddChange("timeout", "3s"),
+	}})
+
+	if !reflect.DeepEqual(keyChanges, [][2]interface{}{{"1s", "2s"}}) {
+		t.Errorf("unexpected key changes:%v", keyChanges)
+	}
+	if !reflect.DeepEqual(prefixKeys, []string{"db.master.host", "db.port"}) {
+		t.Errorf("unexpected prefix keys:%v", prefixKeys)
+	}
+	if !reflect.DeepEqual(globKeys, []string{"db.master.host"}) {
+		t.Errorf("unexpected glob keys:%v", globKeys)
+	}
+
+	unsubscribeKey()
+	unsubscribeKey()
+	unsubscribePrefix()
+	unsubscribeGlob()
+	if n := len(c.getObservers()); n != 0 {
+		t.Errorf("all watchers should be unsubscribed, got:%d", n)
+	}
+}